@@ -166,3 +166,35 @@ func Example_client_SearchStickers() {
 		fmt.Printf("\n\n")
 	}
 }
+
+func Example_client_SearchClips() {
+	client, err := giphy.NewClientFromEnvOrDefault()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res, err := client.SearchClips(context.Background(), &giphy.Request{
+		MaxPageNumber: 2,
+		Query:         "stranger things",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for page := range res.Pages {
+		if page.Err != nil {
+			fmt.Printf("#%d: err: %v\n", page.PageNumber, page.Err)
+			continue
+		}
+
+		for _, clip := range page.Giphs {
+			if clip.Video == nil {
+				continue
+			}
+			fmt.Printf("%s lasts %v\n", clip.ID, clip.Video.Length())
+			for resolution, asset := range clip.Video.Assets {
+				fmt.Printf("\t%s: %s\n", resolution, asset.URL)
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ go 1.21.1
 require (
 	github.com/orijtech/otils v0.0.2
 	go.opencensus.io v0.24.0
+	golang.org/x/text v0.14.0
 )
 
 require github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
@@ -0,0 +1,387 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	"github.com/orijtech/otils"
+)
+
+// Analytics holds the pingback URLs that GIPHY requires
+// integrations to hit as a Giph is shown, clicked and shared.
+type Analytics struct {
+	OnLoad  *Pingback `json:"onload,omitempty"`
+	OnClick *Pingback `json:"onclick,omitempty"`
+	OnSent  *Pingback `json:"onsent,omitempty"`
+}
+
+type Pingback struct {
+	URL string `json:"url"`
+}
+
+type EventType string
+
+const (
+	EventOnLoad  EventType = "onload"
+	EventOnClick EventType = "onclick"
+	EventOnSent  EventType = "onsent"
+)
+
+func (a *Analytics) pingbackURL(typ EventType) string {
+	if a == nil {
+		return ""
+	}
+	var pb *Pingback
+	switch typ {
+	case EventOnLoad:
+		pb = a.OnLoad
+	case EventOnClick:
+		pb = a.OnClick
+	case EventOnSent:
+		pb = a.OnSent
+	}
+	if pb == nil {
+		return ""
+	}
+	return pb.URL
+}
+
+type Event struct {
+	Type      EventType `json:"type"`
+	GiphID    string    `json:"giph_id"`
+	URL       string    `json:"url"`
+	RandomID  string    `json:"random_id"`
+	Timestamp time.Time `json:"ts"`
+}
+
+type ReporterOptions struct {
+	RandomID string `json:"random_id"`
+
+	// BatchSize is the number of pending events that triggers a flush.
+	BatchSize int `json:"batch_size"`
+	// FlushInterval is the longest that an event waits before being sent.
+	FlushInterval time.Duration `json:"flush_interval"`
+
+	// MaxRetries defaults to 3, a negative value disables retrying.
+	MaxRetries   int           `json:"max_retries"`
+	RetryBackoff time.Duration `json:"retry_backoff"`
+
+	// QueueSize is the number of events that may wait to be batched,
+	// defaulting to 1000. Report drops the events that overflow it
+	// rather than blocking while a batch is being sent.
+	QueueSize int `json:"queue_size"`
+
+	// ShutdownTimeout bounds the last flush of pending events on Close
+	// or cancellation, defaulting to 5 seconds, so that an unresponsive
+	// pingback endpoint cannot stall shutting down.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+
+	// OnError, if set, is invoked for every event that could not be
+	// delivered even after retrying, or that overflowed the queue.
+	OnError func(*Event, error) `json:"-"`
+}
+
+const (
+	defaultBatchSize     = 20
+	defaultFlushInterval = 5 * time.Second
+	defaultMaxRetries    = 3
+	defaultRetryBackoff  = 500 * time.Millisecond
+	defaultQueueSize     = 1000
+
+	defaultShutdownTimeout = 5 * time.Second
+)
+
+// AnalyticsReporter batches analytics events and sends their pingbacks.
+type AnalyticsReporter struct {
+	client *Client
+	opts   ReporterOptions
+
+	events    chan *Event
+	flushReqs chan chan error
+
+	closeOnce sync.Once
+	closeChan chan bool
+	done      chan bool
+}
+
+// ErrAnalyticsQueueFull is returned by Report for the
+// events that it drops because its queue is full.
+var ErrAnalyticsQueueFull = errors.New("analytics queue is full, event dropped")
+
+var (
+	errReporterClosed = errors.New("analytics reporter already closed")
+	errNilGiph        = errors.New("expecting a non-nil Giph")
+)
+
+// NewAnalyticsReporter starts a reporter that runs until
+// either ctx is cancelled or Close is invoked, at which
+// point any pending events are flushed one last time,
+// for at most ReporterOptions.ShutdownTimeout.
+func (c *Client) NewAnalyticsReporter(ctx context.Context, opts *ReporterOptions) *AnalyticsReporter {
+	r := &AnalyticsReporter{
+		client:    c,
+		flushReqs: make(chan chan error),
+		closeChan: make(chan bool),
+		done:      make(chan bool),
+	}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.QueueSize <= 0 {
+		r.opts.QueueSize = defaultQueueSize
+	}
+	r.events = make(chan *Event, r.opts.QueueSize)
+	if r.opts.BatchSize <= 0 {
+		r.opts.BatchSize = defaultBatchSize
+	}
+	if r.opts.FlushInterval <= 0 {
+		r.opts.FlushInterval = defaultFlushInterval
+	}
+	if r.opts.MaxRetries < 0 {
+		r.opts.MaxRetries = 0
+	} else if r.opts.MaxRetries == 0 {
+		r.opts.MaxRetries = defaultMaxRetries
+	}
+	if r.opts.RetryBackoff <= 0 {
+		r.opts.RetryBackoff = defaultRetryBackoff
+	}
+	if r.opts.ShutdownTimeout <= 0 {
+		r.opts.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	go r.run(ctx)
+	return r
+}
+
+// NewAnalyticsReporter starts a reporter whose events carry the session's random ID.
+func (s *Session) NewAnalyticsReporter(ctx context.Context, opts *ReporterOptions) *AnalyticsReporter {
+	sopts := new(ReporterOptions)
+	if opts != nil {
+		*sopts = *opts
+	}
+	sopts.RandomID = s.randomID
+	return s.client.NewAnalyticsReporter(ctx, sopts)
+}
+
+// Report queues an event of type typ for giph without ever blocking:
+// if the queue is full, the event is dropped with ErrAnalyticsQueueFull.
+func (r *AnalyticsReporter) Report(giph *Giph, typ EventType) error {
+	if giph == nil {
+		return errNilGiph
+	}
+	pingbackURL := giph.Analytics.pingbackURL(typ)
+	if pingbackURL == "" {
+		return fmt.Errorf("giph %q has no %q analytics URL", giph.ID, typ)
+	}
+	event := &Event{
+		Type:      typ,
+		GiphID:    giph.ID,
+		URL:       pingbackURL,
+		RandomID:  r.opts.RandomID,
+		Timestamp: time.Now(),
+	}
+	select {
+	case <-r.done:
+		return errReporterClosed
+	default:
+	}
+	select {
+	case r.events <- event:
+		return nil
+	default:
+		if r.opts.OnError != nil {
+			r.opts.OnError(event, ErrAnalyticsQueueFull)
+		}
+		return ErrAnalyticsQueueFull
+	}
+}
+
+// Flush sends all the pending events, returning the first delivery error.
+func (r *AnalyticsReporter) Flush() error {
+	errChan := make(chan error, 1)
+	select {
+	case r.flushReqs <- errChan:
+		return <-errChan
+	case <-r.done:
+		return errReporterClosed
+	}
+}
+
+// Close flushes pending events and stops the reporter.
+func (r *AnalyticsReporter) Close() error {
+	err := errReporterClosed
+	r.closeOnce.Do(func() {
+		close(r.closeChan)
+		err = nil
+	})
+	<-r.done
+	return err
+}
+
+func (r *AnalyticsReporter) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []*Event
+	flush := func(ctx context.Context) error {
+		// Take in the queued events too, which the
+		// select below may not have gotten to yet.
+		for drained := false; !drained; {
+			select {
+			case event := <-r.events:
+				batch = append(batch, event)
+			default:
+				drained = true
+			}
+		}
+		err := r.send(ctx, batch)
+		batch = nil
+		return err
+	}
+
+loop:
+	for {
+		select {
+		case event := <-r.events:
+			batch = append(batch, event)
+			if len(batch) >= r.opts.BatchSize {
+				flush(ctx)
+			}
+
+		case errChan := <-r.flushReqs:
+			errChan <- flush(ctx)
+
+		case <-ticker.C:
+			flush(ctx)
+
+		case <-r.closeChan:
+			break loop
+
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	if ctx.Err() != nil {
+		// The parent context is done, so give the
+		// pending events a single detached attempt.
+		r.opts.MaxRetries = 0
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.opts.ShutdownTimeout)
+	defer cancel()
+	flush(ctx)
+}
+
+func (r *AnalyticsReporter) send(ctx context.Context, events []*Event) error {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*AnalyticsReporter).send")
+	defer span.End()
+
+	var firstErr error
+	if len(events) == 0 {
+		return nil
+	}
+	for _, event := range events {
+		err := r.sendWithRetries(ctx, event)
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if r.opts.OnError != nil {
+			r.opts.OnError(event, err)
+		}
+	}
+	return firstErr
+}
+
+func (r *AnalyticsReporter) sendWithRetries(ctx context.Context, event *Event) error {
+	var err error
+	backoff := r.opts.RetryBackoff
+	for attempt := 0; attempt <= r.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = r.sendOne(ctx, event); err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// pingbackStatusError is the failure of a pingback that got a response.
+type pingbackStatusError struct {
+	code   int
+	status string
+}
+
+func (pse *pingbackStatusError) Error() string {
+	return pse.status
+}
+
+// retryable reports whether sending a pingback again could succeed
+// after err, that is after network errors, 429s and 5XXs.
+func retryable(err error) bool {
+	var pse *pingbackStatusError
+	if errors.As(err, &pse) {
+		return pse.code == http.StatusTooManyRequests || pse.code >= 500
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (r *AnalyticsReporter) sendOne(ctx context.Context, event *Event) error {
+	parsedURL, err := url.Parse(event.URL)
+	if err != nil {
+		return err
+	}
+	qv := parsedURL.Query()
+	if event.RandomID != "" {
+		qv.Set("random_id", event.RandomID)
+	}
+	qv.Set("ts", strconv.FormatInt(event.Timestamp.UnixNano()/int64(time.Millisecond), 10))
+	parsedURL.RawQuery = qv.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", parsedURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	res, err := r.client.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if !otils.StatusOK(res.StatusCode) {
+		return &pingbackStatusError{code: res.StatusCode, status: res.Status}
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+type pingbackRecorder struct {
+	mu       sync.Mutex
+	queries  []url.Values
+	failures int
+	// failStatus is the status of failures, defaulting to 503.
+	failStatus int
+	attempts   int
+}
+
+func (pr *pingbackRecorder) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.attempts++
+	if pr.failures > 0 {
+		pr.failures -= 1
+		status := pr.failStatus
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(rw, "try again", status)
+		return
+	}
+	pr.queries = append(pr.queries, req.URL.Query())
+}
+
+func (pr *pingbackRecorder) received() []url.Values {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	return append([]url.Values(nil), pr.queries...)
+}
+
+func analyticsGiph(id, serverURL string) *giphy.Giph {
+	return &giphy.Giph{
+		ID: id,
+		Analytics: &giphy.Analytics{
+			OnLoad:  &giphy.Pingback{URL: serverURL + "/?action_type=SEEN&gif_id=" + id},
+			OnClick: &giphy.Pingback{URL: serverURL + "/?action_type=CLICK&gif_id=" + id},
+		},
+	}
+}
+
+func TestGiphAnalyticsDecoding(t *testing.T) {
+	blob, err := ioutil.ReadFile("./testdata/gif-3ohze2UfcItWPUFqbm.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrap := new(struct {
+		Giph *giphy.Giph `json:"data"`
+	})
+	if err := json.Unmarshal(blob, wrap); err != nil {
+		t.Fatal(err)
+	}
+	analytics := wrap.Giph.Analytics
+	if analytics == nil {
+		t.Fatal("expected non-nil analytics")
+	}
+	for typ, pb := range map[string]*giphy.Pingback{"SEEN": analytics.OnLoad, "CLICK": analytics.OnClick, "SENT": analytics.OnSent} {
+		if pb == nil || !strings.HasSuffix(pb.URL, "action_type="+typ) {
+			t.Errorf("%s: unexpected pingback: %#v", typ, pb)
+		}
+	}
+}
+
+func TestAnalyticsReporterBatching(t *testing.T) {
+	recorder := new(pingbackRecorder)
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := client.ResumeSession(testRandomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reporter := session.NewAnalyticsReporter(context.Background(), &giphy.ReporterOptions{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	defer reporter.Close()
+
+	giph := analyticsGiph("abc", server.URL)
+	if err := reporter.Report(giph, giphy.EventOnLoad); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(recorder.received()); got != 0 {
+		t.Fatalf("a partial batch must not be sent, got %d pingbacks", got)
+	}
+	if err := reporter.Report(giph, giphy.EventOnClick); err != nil {
+		t.Fatal(err)
+	}
+	if err := reporter.Report(giph, giphy.EventOnSent); err == nil {
+		t.Errorf("expected an error for a missing onsent URL")
+	}
+	// Flushing an empty batch syncs with the reporter
+	// so the filled batch has definitely been sent.
+	if err := reporter.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := recorder.received()
+	if len(queries) != 2 {
+		t.Fatalf("gotPingbacks: %d wantPingbacks: 2", len(queries))
+	}
+	for i, qv := range queries {
+		if got := qv.Get("random_id"); got != testRandomID {
+			t.Errorf("#%d: gotRandomID: %q wantRandomID: %q", i, got, testRandomID)
+		}
+		if qv.Get("ts") == "" {
+			t.Errorf("#%d: expected a timestamp", i)
+		}
+	}
+	if got, want := queries[1].Get("action_type"), "CLICK"; got != want {
+		t.Errorf("gotAction: %q wantAction: %q", got, want)
+	}
+}
+
+func TestAnalyticsReporterRetriesAndShutdown(t *testing.T) {
+	recorder := &pingbackRecorder{failures: 2}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reporter := client.NewAnalyticsReporter(ctx, &giphy.ReporterOptions{
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	})
+
+	giph := analyticsGiph("xyz", server.URL)
+	if err := reporter.Report(giph, giphy.EventOnLoad); err != nil {
+		t.Fatal(err)
+	}
+	if err := reporter.Flush(); err != nil {
+		t.Fatalf("expected the retries to succeed, got: %v", err)
+	}
+	if got := len(recorder.received()); got != 1 {
+		t.Fatalf("gotPingbacks: %d wantPingbacks: 1", got)
+	}
+
+	if err := reporter.Report(giph, giphy.EventOnClick); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := reporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(recorder.received()); got != 2 {
+		t.Errorf("pending events must be flushed on shutdown, gotPingbacks: %d", got)
+	}
+	if err := reporter.Report(giph, giphy.EventOnLoad); err == nil {
+		t.Errorf("expected an error after the reporter was closed")
+	}
+	if err := reporter.Close(); err == nil {
+		t.Errorf("expected an error on closing twice")
+	}
+}
+
+func TestAnalyticsReporterRetriesOnlyTransientErrors(t *testing.T) {
+	tests := [...]struct {
+		failStatus   int
+		wantAttempts int
+		wantErr      bool
+	}{
+		0: {failStatus: http.StatusServiceUnavailable, wantAttempts: 3},
+		1: {failStatus: http.StatusTooManyRequests, wantAttempts: 3},
+		2: {failStatus: http.StatusNotFound, wantAttempts: 1, wantErr: true},
+		3: {failStatus: http.StatusBadRequest, wantAttempts: 1, wantErr: true},
+	}
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		recorder := &pingbackRecorder{failures: 2, failStatus: tt.failStatus}
+		server := httptest.NewServer(recorder)
+
+		reporter := client.NewAnalyticsReporter(context.Background(), &giphy.ReporterOptions{
+			FlushInterval: time.Hour,
+			RetryBackoff:  time.Millisecond,
+		})
+		if err := reporter.Report(analyticsGiph("retry", server.URL), giphy.EventOnLoad); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		err := reporter.Flush()
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("#%d: gotErr: %v wantErr: %t", i, err, tt.wantErr)
+		}
+		recorder.mu.Lock()
+		if recorder.attempts != tt.wantAttempts {
+			t.Errorf("#%d: gotAttempts: %d wantAttempts: %d", i, recorder.attempts, tt.wantAttempts)
+		}
+		recorder.mu.Unlock()
+
+		reporter.Close()
+		server.Close()
+	}
+}
+
+func TestAnalyticsReporterQueueOverflow(t *testing.T) {
+	arrived := make(chan bool, 1)
+	release := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case arrived <- true:
+		default:
+		}
+		<-release
+	}))
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var dropped []error
+	reporter := client.NewAnalyticsReporter(context.Background(), &giphy.ReporterOptions{
+		BatchSize:     1,
+		QueueSize:     1,
+		FlushInterval: time.Hour,
+		OnError: func(event *giphy.Event, err error) {
+			mu.Lock()
+			dropped = append(dropped, err)
+			mu.Unlock()
+		},
+	})
+
+	giph := analyticsGiph("busy", server.URL)
+	if err := reporter.Report(giph, giphy.EventOnLoad); err != nil {
+		t.Fatal(err)
+	}
+	// The reporter is now stuck sending the first event.
+	<-arrived
+
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		if err := reporter.Report(giph, giphy.EventOnClick); err != nil {
+			t.Errorf("the queue has room for one event, got: %v", err)
+		}
+		if err := reporter.Report(giph, giphy.EventOnLoad); err != giphy.ErrAnalyticsQueueFull {
+			t.Errorf("gotErr: %v wantErr: %v", err, giphy.ErrAnalyticsQueueFull)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Report blocked while a batch was being sent")
+	}
+
+	close(release)
+	if err := reporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dropped) != 1 || dropped[0] != giphy.ErrAnalyticsQueueFull {
+		t.Errorf("gotDropped: %v want just the overflowing event", dropped)
+	}
+}
+
+func TestAnalyticsReporterShutdownTimeout(t *testing.T) {
+	release := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var failures []error
+	ctx, cancel := context.WithCancel(context.Background())
+	reporter := client.NewAnalyticsReporter(ctx, &giphy.ReporterOptions{
+		FlushInterval:   time.Hour,
+		ShutdownTimeout: 50 * time.Millisecond,
+		OnError: func(event *giphy.Event, err error) {
+			mu.Lock()
+			failures = append(failures, err)
+			mu.Unlock()
+		},
+	})
+	if err := reporter.Report(analyticsGiph("stalled", server.URL), giphy.EventOnLoad); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	closed := make(chan bool)
+	go func() {
+		defer close(closed)
+		reporter.Close()
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on an unresponsive pingback endpoint")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failures) != 1 {
+		t.Errorf("gotFailures: %v want the stalled event reported", failures)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"bytes"
+	"context"
+	"image/gif"
+	"io"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+// AnimationInfo describes the frames of a GIF.
+type AnimationInfo struct {
+	// Width and Height are those of the logical screen
+	// that every frame is drawn onto.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	FrameCount int `json:"frame_count"`
+	// Delays holds how long each frame is shown for. Browsers
+	// typically stretch delays below 20ms to 100ms.
+	Delays   []time.Duration `json:"delays"`
+	Duration time.Duration   `json:"duration"`
+
+	// Disposals holds the disposal method of each frame,
+	// as one of the gif.Disposal* constants.
+	Disposals []byte `json:"disposals"`
+
+	// LoopCount is 0 for endless looping, -1 for playing just
+	// once and otherwise the number of times to replay.
+	LoopCount int `json:"loop_count"`
+}
+
+func (ai *AnimationInfo) LoopsForever() bool {
+	return ai != nil && ai.LoopCount == 0
+}
+
+// InspectGIF decodes the GIF in r to describe its animation.
+func InspectGIF(r io.Reader) (*AnimationInfo, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return animationInfo(g), nil
+}
+
+func animationInfo(g *gif.GIF) *AnimationInfo {
+	info := &AnimationInfo{
+		Width:      g.Config.Width,
+		Height:     g.Config.Height,
+		FrameCount: len(g.Image),
+		Delays:     make([]time.Duration, len(g.Image)),
+		Disposals:  make([]byte, len(g.Image)),
+		LoopCount:  g.LoopCount,
+	}
+	for i := range g.Image {
+		if i < len(g.Delay) {
+			// Delays are in hundredths of a second.
+			info.Delays[i] = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+			info.Duration += info.Delays[i]
+		}
+		if i < len(g.Disposal) {
+			info.Disposals[i] = g.Disposal[i]
+		}
+	}
+	return info
+}
+
+// decodeRendition downloads rendition r of giph and decodes all its frames.
+func (c *Client) decodeRendition(ctx context.Context, giph *Giph, r Rendition) (*gif.GIF, error) {
+	buf := new(bytes.Buffer)
+	dreq := &DownloadRequest{Giph: giph, Rendition: r, Format: MediaGIF}
+	if _, err := c.Download(ctx, dreq, buf); err != nil {
+		return nil, err
+	}
+	return gif.DecodeAll(buf)
+}
+
+// Animation returns the AnimationInfo of rendition r of giph, defaulting
+// to RenditionOriginal. The first call per rendition downloads and decodes
+// it, then saves the result in the rendition's GIF.Animation, along with
+// its Frames and dimensions if GIPHY did not report them. Enriching the
+// same Giph from several goroutines at once is not safe.
+func (c *Client) Animation(ctx context.Context, giph *Giph, r Rendition) (*AnimationInfo, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Animation")
+	defer span.End()
+
+	if r == "" {
+		r = RenditionOriginal
+	}
+	if size := giph.Rendition(r); size != nil && size.Animation != nil {
+		return size.Animation, nil
+	}
+
+	g, err := c.decodeRendition(ctx, giph, r)
+	if err != nil {
+		return nil, err
+	}
+	info := animationInfo(g)
+
+	size := giph.Rendition(r)
+	size.Animation = info
+	if size.Frames == 0 {
+		size.Frames = uint(info.FrameCount)
+	}
+	if size.Width == 0 || size.Height == 0 {
+		size.Width, size.Height = info.Width, info.Height
+	}
+	if r == RenditionOriginal && giph.FrameCount == 0 {
+		giph.FrameCount = uint(info.FrameCount)
+	}
+	return info, nil
+}
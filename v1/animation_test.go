@@ -0,0 +1,150 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+// frameColors are the solid colors of the frames of test GIFs.
+var frameColors = []color.RGBA{
+	{R: 0xff, A: 0xff},
+	{G: 0xff, A: 0xff},
+	{B: 0xff, A: 0xff},
+	{R: 0xff, G: 0xff, A: 0xff},
+}
+
+// makeTestGIF encodes an animation of width by height whose frames are
+// each filled with the next of frameColors and shown for 10ms*(i+1).
+func makeTestGIF(t *testing.T, frames, width, height int) []byte {
+	g := &gif.GIF{LoopCount: 2}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+		fill := frame.Palette.Index(frameColors[i%len(frameColors)])
+		for j := range frame.Pix {
+			frame.Pix[j] = uint8(fill)
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, i+1)
+		g.Disposal = append(g.Disposal, gif.DisposalBackground)
+	}
+	buf := new(bytes.Buffer)
+	if err := gif.EncodeAll(buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// gifServer serves body as "/{id}.gif" for any id, counting the requests.
+func gifServer(body []byte, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if hits != nil {
+			atomic.AddInt32(hits, 1)
+		}
+		rw.Header().Set("Content-Type", "image/gif")
+		rw.Write(body)
+	}))
+}
+
+func TestInspectGIF(t *testing.T) {
+	info, err := giphy.InspectGIF(bytes.NewReader(makeTestGIF(t, 3, 40, 30)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &giphy.AnimationInfo{
+		Width: 40, Height: 30, FrameCount: 3,
+		Delays:    []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond},
+		Duration:  60 * time.Millisecond,
+		Disposals: []byte{gif.DisposalBackground, gif.DisposalBackground, gif.DisposalBackground},
+		LoopCount: 2,
+	}
+	if info.Width != want.Width || info.Height != want.Height || info.FrameCount != want.FrameCount ||
+		info.Duration != want.Duration || info.LoopCount != want.LoopCount {
+		t.Errorf("got %+v want %+v", info, want)
+	}
+	for i := range want.Delays {
+		if info.Delays[i] != want.Delays[i] || info.Disposals[i] != want.Disposals[i] {
+			t.Errorf("frame #%d: got delay %s disposal %d want %s and %d",
+				i, info.Delays[i], info.Disposals[i], want.Delays[i], want.Disposals[i])
+		}
+	}
+	if info.LoopsForever() {
+		t.Error("expected a finite loop count")
+	}
+
+	if _, err := giphy.InspectGIF(strings.NewReader("not a gif")); err == nil {
+		t.Error("expected an error for a non-GIF")
+	}
+}
+
+func TestClientAnimation(t *testing.T) {
+	var hits int32
+	server := gifServer(makeTestGIF(t, 4, 16, 12), &hits)
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	giph := &giphy.Giph{
+		ID: "animated",
+		Sizes: map[string]*giphy.GIF{
+			"original":     {URL: server.URL + "/animated.gif"},
+			"fixed_height": {URL: server.URL + "/200.gif", Width: 16, Height: 12, Frames: 9},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		info, err := client.Animation(context.Background(), giph, "")
+		if err != nil {
+			t.Fatalf("#%d: err: %v", i, err)
+		}
+		if info.FrameCount != 4 || info.Duration != 100*time.Millisecond {
+			t.Errorf("#%d: got %+v", i, info)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("got %d downloads want 1, the result must be saved on the Giph", hits)
+	}
+	original := giph.Rendition(giphy.RenditionOriginal)
+	if original.Animation == nil || original.Frames != 4 || original.Width != 16 || original.Height != 12 || giph.FrameCount != 4 {
+		t.Errorf("the original rendition was not enriched: %+v", original)
+	}
+
+	// What GIPHY reported is left alone.
+	if _, err := client.Animation(context.Background(), giph, giphy.RenditionFixedHeight); err != nil {
+		t.Fatal(err)
+	}
+	if fixedHeight := giph.Rendition(giphy.RenditionFixedHeight); fixedHeight.Frames != 9 || fixedHeight.Animation.FrameCount != 4 {
+		t.Errorf("got %+v", fixedHeight)
+	}
+
+	if _, err := client.Animation(context.Background(), giph, giphy.RenditionHD); err == nil {
+		t.Error("expected an error for a missing rendition")
+	}
+}
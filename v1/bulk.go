@@ -0,0 +1,453 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+type BulkOptions struct {
+	// Dir is where files are saved. It must already exist.
+	Dir string `json:"dir"`
+
+	// Workers is the number of concurrent downloads, defaulting to 4.
+	Workers int `json:"workers"`
+
+	// BytesPerSecond caps the combined download rate
+	// of all workers. Zero means no limit.
+	BytesPerSecond int64 `json:"bytes_per_second"`
+
+	// Rendition and Format select the media of each Giph
+	// as they do in a DownloadRequest.
+	Rendition Rendition   `json:"rendition"`
+	Format    MediaFormat `json:"format"`
+
+	// Filename names the file saved for a Giph, defaulting
+	// to its ID followed by the rendition, if not the original,
+	// and the extension of Format e.g. "3o7btPg4hsI2t52hUc.gif".
+	Filename func(*Giph) string `json:"-"`
+
+	// OnEvent, if set, is notified as each download progresses.
+	// Calls are never made concurrently.
+	OnEvent func(*DownloadEvent) `json:"-"`
+}
+
+type DownloadEventKind string
+
+const (
+	DownloadStarted   DownloadEventKind = "started"
+	DownloadProgress  DownloadEventKind = "progress"
+	DownloadCompleted DownloadEventKind = "completed"
+	DownloadSkipped   DownloadEventKind = "skipped"
+	DownloadFailed    DownloadEventKind = "failed"
+)
+
+type DownloadEvent struct {
+	Kind   DownloadEventKind `json:"kind"`
+	GiphID string            `json:"giph_id"`
+	Path   string            `json:"path"`
+
+	// Received includes the bytes of a resumed partial download.
+	Received int64 `json:"received"`
+	// Total is -1 if GIPHY did not report the size.
+	Total int64 `json:"total"`
+	// Resumed is set if the download continued from a partial file.
+	Resumed bool `json:"resumed,omitempty"`
+
+	Err error `json:"error,omitempty"`
+}
+
+type BulkSummary struct {
+	Completed int   `json:"completed"`
+	Skipped   int   `json:"skipped"`
+	Failed    int   `json:"failed"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// partialSuffix marks files that are still being downloaded.
+const partialSuffix = ".part"
+
+var errBlankBulkDir = errors.New("expecting a non-blank Dir")
+
+// BulkDownload saves the Giphs of every page of pager into opts.Dir,
+// downloading them as pages arrive. Each file is written under a ".part"
+// suffix and renamed once complete, so a crash never leaves a truncated
+// file behind, and a later BulkDownload resumes from the partial file.
+// Files that already exist are skipped.
+//
+// It returns once pager is exhausted and all downloads have finished,
+// or ctx is done. Failed downloads are reported to OnEvent and counted
+// in the summary; the returned error is that of a failed page, if any.
+func (c *Client) BulkDownload(ctx context.Context, pager *ResponsePager, opts *BulkOptions) (*BulkSummary, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).BulkDownload")
+	defer span.End()
+
+	if opts == nil || opts.Dir == "" {
+		return nil, errBlankBulkDir
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	bd := &bulkDownloader{
+		client:  c,
+		opts:    opts,
+		limiter: newRateLimiter(opts.BytesPerSecond),
+		summary: new(BulkSummary),
+		claimed: make(map[string]bool),
+	}
+
+	giphsChan := make(chan *Giph)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for giph := range giphsChan {
+				bd.download(ctx, giph)
+			}
+		}()
+	}
+
+	var pageErr error
+feed:
+	for {
+		select {
+		case <-ctx.Done():
+			pageErr = ctx.Err()
+			break feed
+		case page, ok := <-pager.Pages:
+			if !ok {
+				break feed
+			}
+			// A failed Page may still carry the Giphs that it got before failing.
+			for _, giph := range page.Giphs {
+				select {
+				case giphsChan <- giph:
+				case <-ctx.Done():
+					pageErr = ctx.Err()
+					break feed
+				}
+			}
+			if page.Err != nil {
+				pageErr = page.Err
+				break feed
+			}
+		}
+	}
+	if pageErr != nil && pager.Cancel != nil {
+		pager.Cancel()
+		// Unblock the pager, which may be sending a Page, until it stops.
+		go func() {
+			for range pager.Pages {
+			}
+		}()
+	}
+	close(giphsChan)
+	wg.Wait()
+
+	return bd.summary, pageErr
+}
+
+type bulkDownloader struct {
+	client  *Client
+	opts    *BulkOptions
+	limiter *rateLimiter
+
+	mu      sync.Mutex
+	summary *BulkSummary
+	// claimed holds the paths being downloaded, as a
+	// Giph may appear more than once across pages.
+	claimed map[string]bool
+}
+
+func (bd *bulkDownloader) filename(giph *Giph) string {
+	if bd.opts.Filename != nil {
+		return bd.opts.Filename(giph)
+	}
+	name := giph.ID
+	if r := bd.opts.Rendition; r != "" && r != RenditionOriginal {
+		name += "-" + string(r)
+	}
+	format := bd.opts.Format
+	if format == "" {
+		format = MediaGIF
+	}
+	return name + "." + string(format)
+}
+
+// emit records ev in the summary and passes it on to OnEvent.
+func (bd *bulkDownloader) emit(ev *DownloadEvent) {
+	bd.mu.Lock()
+	defer bd.mu.Unlock()
+
+	switch ev.Kind {
+	case DownloadCompleted:
+		bd.summary.Completed += 1
+	case DownloadSkipped:
+		bd.summary.Skipped += 1
+	case DownloadFailed:
+		bd.summary.Failed += 1
+	}
+	if bd.opts.OnEvent != nil {
+		// ev is updated as the download goes on, so pass a copy.
+		copied := *ev
+		bd.opts.OnEvent(&copied)
+	}
+}
+
+func (bd *bulkDownloader) claim(path string) bool {
+	bd.mu.Lock()
+	defer bd.mu.Unlock()
+
+	if bd.claimed[path] {
+		return false
+	}
+	bd.claimed[path] = true
+	return true
+}
+
+func (bd *bulkDownloader) download(ctx context.Context, giph *Giph) {
+	path := filepath.Join(bd.opts.Dir, bd.filename(giph))
+	ev := &DownloadEvent{GiphID: giph.ID, Path: path, Total: -1}
+
+	if !bd.claim(path) {
+		ev.Kind = DownloadSkipped
+		bd.emit(ev)
+		return
+	}
+	if _, err := os.Stat(path); err == nil {
+		ev.Kind = DownloadSkipped
+		bd.emit(ev)
+		return
+	}
+
+	n, err := bd.fetch(ctx, giph, path, ev)
+	ev.Received = n
+	if err != nil {
+		ev.Kind, ev.Err = DownloadFailed, err
+		bd.emit(ev)
+		return
+	}
+	ev.Kind = DownloadCompleted
+	bd.emit(ev)
+}
+
+// fetch downloads giph into path+partialSuffix, resuming from whatever the
+// partial file already holds, then renames it to path. It returns the size
+// of the partial file, which is kept on failure so that it can be resumed.
+func (bd *bulkDownloader) fetch(ctx context.Context, giph *Giph, path string, ev *DownloadEvent) (int64, error) {
+	dreq := &DownloadRequest{Giph: giph, Rendition: bd.opts.Rendition, Format: bd.opts.Format}
+	theURL, size, err := dreq.media()
+	if err != nil {
+		return 0, err
+	}
+	if size > 0 {
+		ev.Total = size
+	}
+
+	partPath := path + partialSuffix
+	part, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	// finish owns the close of part, which is only closed here on failure.
+	finished := false
+	defer func() {
+		if !finished {
+			part.Close()
+		}
+	}()
+	finish := func() error {
+		finished = true
+		return bd.finish(part, partPath, path)
+	}
+
+	offset, err := part.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	restart := func() error {
+		if err := part.Truncate(0); err != nil {
+			return err
+		}
+		offset, err = part.Seek(0, io.SeekStart)
+		return err
+	}
+	if size > 0 && offset > size {
+		// Not a prefix of this media, so start over.
+		if err := restart(); err != nil {
+			return 0, err
+		}
+	}
+	if size > 0 && offset == size {
+		// A previous run received everything but did not get to rename.
+		return offset, finish()
+	}
+
+	res, err := bd.client.openMedia(ctx, theURL, offset)
+	if err != nil {
+		if offset > 0 && res != nil && res.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// The partial file already holds all of the media.
+			return offset, finish()
+		}
+		return offset, err
+	}
+	defer res.Body.Close()
+
+	if offset > 0 && res.StatusCode != http.StatusPartialContent {
+		// The server ignored the Range header, so start over.
+		if err := restart(); err != nil {
+			return 0, err
+		}
+	}
+
+	body := bufio.NewReader(res.Body)
+	if offset == 0 {
+		if err := checkContentType(theURL, res.Header.Get("Content-Type"), body, dreq.format()); err != nil {
+			return 0, err
+		}
+	}
+
+	ev.Kind, ev.Received, ev.Resumed = DownloadStarted, offset, offset > 0
+	bd.emit(ev)
+
+	n, err := bd.copy(ctx, part, body, offset, ev)
+	received := offset + n
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		return received, err
+	}
+	if size > 0 && received != size {
+		// The partial file cannot be trusted, so do not resume from it.
+		os.Remove(partPath)
+		return received, &SizeMismatchError{URL: theURL, Want: size, Got: received}
+	}
+	return received, finish()
+}
+
+// copy streams src into dst at the rate that the limiter allows,
+// emitting a DownloadProgress event for every chunk written.
+func (bd *bulkDownloader) copy(ctx context.Context, dst io.Writer, src io.Reader, offset int64, ev *DownloadEvent) (int64, error) {
+	buf := make([]byte, bd.limiter.chunkSize())
+	var written int64
+	for {
+		nr, rerr := src.Read(buf)
+		if nr > 0 {
+			if err := bd.limiter.wait(ctx, nr); err != nil {
+				return written, err
+			}
+			nw, werr := dst.Write(buf[:nr])
+			written += int64(nw)
+			if werr != nil {
+				return written, werr
+			}
+			bd.mu.Lock()
+			bd.summary.Bytes += int64(nw)
+			bd.mu.Unlock()
+
+			ev.Kind, ev.Received = DownloadProgress, offset+written
+			bd.emit(ev)
+		}
+		if rerr == io.EOF {
+			return written, nil
+		}
+		if rerr != nil {
+			return written, rerr
+		}
+	}
+}
+
+// finish closes part and, once it is safely on disk, renames it to path.
+func (bd *bulkDownloader) finish(part *os.File, partPath, path string) error {
+	if err := part.Sync(); err != nil {
+		part.Close()
+		return err
+	}
+	if err := part.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partPath, path)
+}
+
+// rateLimiter is a token bucket shared by all the workers of a
+// BulkDownload. It starts empty and holds up to a second's worth of bytes.
+type rateLimiter struct {
+	bytesPerSecond int64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	return &rateLimiter{bytesPerSecond: bytesPerSecond, last: time.Now()}
+}
+
+const maxChunkSize = 32 * 1024
+
+// chunkSize keeps reads small enough that the rate stays smooth.
+func (rl *rateLimiter) chunkSize() int {
+	if rl.bytesPerSecond > 0 && rl.bytesPerSecond < maxChunkSize {
+		return int(rl.bytesPerSecond)
+	}
+	return maxChunkSize
+}
+
+// wait blocks until n bytes may be transferred or ctx is done.
+func (rl *rateLimiter) wait(ctx context.Context, n int) error {
+	if rl.bytesPerSecond <= 0 {
+		return nil
+	}
+
+	rl.mu.Lock()
+	now := time.Now()
+	rate := float64(rl.bytesPerSecond)
+	rl.tokens += now.Sub(rl.last).Seconds() * rate
+	if rl.tokens > rate {
+		rl.tokens = rate
+	}
+	rl.last = now
+	// Tokens may go negative, reserving the bytes of waiting workers.
+	rl.tokens -= float64(n)
+	deficit := -rl.tokens
+	rl.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(deficit / rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
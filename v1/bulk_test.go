@@ -0,0 +1,253 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+// bulkServer serves a GIF of size bytes for every "/{id}.gif",
+// honoring Range requests, and records the ranges that were asked for.
+type bulkServer struct {
+	*httptest.Server
+	size int
+
+	mu     sync.Mutex
+	ranges map[string]string
+}
+
+func newBulkServer(size int) *bulkServer {
+	bs := &bulkServer{size: size, ranges: make(map[string]string)}
+	bs.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		name := path.Base(req.URL.Path)
+		bs.mu.Lock()
+		bs.ranges[name] = req.Header.Get("Range")
+		bs.mu.Unlock()
+		http.ServeContent(rw, req, name, time.Time{}, bytes.NewReader(bs.body(strings.TrimSuffix(name, ".gif"))))
+	}))
+	return bs
+}
+
+func (bs *bulkServer) body(id string) []byte {
+	body := append([]byte("GIF89a"+id), bytes.Repeat([]byte{0x2c}, bs.size)...)
+	return body[:bs.size]
+}
+
+func (bs *bulkServer) giph(id string) *giphy.Giph {
+	return &giphy.Giph{
+		ID: id,
+		Sizes: map[string]*giphy.GIF{
+			"original": {URL: fmt.Sprintf("%s/%s.gif", bs.URL, id), Size: int64(bs.size)},
+		},
+	}
+}
+
+func pagerOf(pages ...*giphy.Page) *giphy.ResponsePager {
+	pagesChan := make(chan *giphy.Page, len(pages))
+	for _, page := range pages {
+		pagesChan <- page
+	}
+	close(pagesChan)
+	return &giphy.ResponsePager{Pages: pagesChan, Cancel: func() error { return nil }}
+}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []*giphy.DownloadEvent
+}
+
+func (el *eventLog) record(ev *giphy.DownloadEvent) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	el.events = append(el.events, ev)
+}
+
+func (el *eventLog) kinds(giphID string) map[giphy.DownloadEventKind]int {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	kinds := make(map[giphy.DownloadEventKind]int)
+	for _, ev := range el.events {
+		if ev.GiphID == giphID {
+			kinds[ev.Kind]++
+		}
+	}
+	return kinds
+}
+
+func TestBulkDownload(t *testing.T) {
+	server := newBulkServer(70 * 1024)
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	// A leftover from an interrupted run.
+	if err := os.WriteFile(filepath.Join(dir, "resumed.gif.part"), server.body("resumed")[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	// An earlier complete download.
+	if err := os.WriteFile(filepath.Join(dir, "existing.gif"), []byte("done"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	broken := server.giph("broken")
+	broken.Sizes["original"].Size += 10
+
+	pager := pagerOf(
+		&giphy.Page{Giphs: []*giphy.Giph{server.giph("a"), server.giph("b"), server.giph("resumed")}},
+		&giphy.Page{Giphs: []*giphy.Giph{server.giph("c"), server.giph("a"), server.giph("existing"), broken}},
+	)
+	log := new(eventLog)
+	summary, err := client.BulkDownload(context.Background(), pager, &giphy.BulkOptions{
+		Dir:     dir,
+		Workers: 3,
+		OnEvent: log.record,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := giphy.BulkSummary{Completed: 4, Skipped: 2, Failed: 1, Bytes: 4*70*1024 - 1000 + 70*1024}
+	if *summary != want {
+		t.Errorf("gotSummary: %+v wantSummary: %+v", summary, want)
+	}
+
+	for _, id := range []string{"a", "b", "c", "resumed"} {
+		got, err := os.ReadFile(filepath.Join(dir, id+".gif"))
+		if err != nil || !bytes.Equal(got, server.body(id)) {
+			t.Errorf("%s: got %d bytes err=%v", id, len(got), err)
+		}
+		kinds := log.kinds(id)
+		if kinds[giphy.DownloadStarted] != 1 || kinds[giphy.DownloadCompleted] != 1 || kinds[giphy.DownloadProgress] == 0 {
+			t.Errorf("%s: unexpected events %v", id, kinds)
+		}
+	}
+	if got := server.ranges["resumed.gif"]; got != "bytes=1000-" {
+		t.Errorf("resumed: got Range %q want %q", got, "bytes=1000-")
+	}
+	if _, requested := server.ranges["existing.gif"]; requested {
+		t.Error("existing: expected no request for a file that already exists")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.gif")); !os.IsNotExist(err) {
+		t.Errorf("broken: expected no file, got err=%v", err)
+	}
+	var sme *giphy.SizeMismatchError
+	for _, ev := range log.events {
+		if ev.GiphID == "broken" && ev.Kind == giphy.DownloadFailed && !errors.As(ev.Err, &sme) {
+			t.Errorf("broken: gotErr: %v want a *SizeMismatchError", ev.Err)
+		}
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "*.part"))
+	if len(leftovers) != 0 {
+		t.Errorf("unexpected partial files: %q", leftovers)
+	}
+}
+
+func TestBulkDownloadBandwidthCap(t *testing.T) {
+	const size = 4 * 1024
+	server := newBulkServer(size)
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pager := pagerOf(&giphy.Page{Giphs: []*giphy.Giph{server.giph("a"), server.giph("b"), server.giph("c"), server.giph("d")}})
+	start := time.Now()
+	// The limiter starts out empty, so this should take about a second.
+	summary, err := client.BulkDownload(context.Background(), pager, &giphy.BulkOptions{
+		Dir:            t.TempDir(),
+		Workers:        4,
+		BytesPerSecond: 4 * size,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Completed != 4 {
+		t.Errorf("got summary %+v want 4 completed", summary)
+	}
+	if elapsed := time.Since(start); elapsed < 750*time.Millisecond {
+		t.Errorf("downloading %d bytes at %d bytes/s took only %s", 4*size, 4*size, elapsed)
+	}
+}
+
+func TestBulkDownloadPageError(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageErr := errors.New("page failed")
+	pager := pagerOf(&giphy.Page{Err: pageErr})
+	if _, err := client.BulkDownload(context.Background(), pager, &giphy.BulkOptions{Dir: t.TempDir()}); err != pageErr {
+		t.Errorf("gotErr: %v wantErr: %v", err, pageErr)
+	}
+
+	// The Giphs of the failed page are still downloaded, and the
+	// pager, which keeps sending pages until it is cancelled, is
+	// unblocked instead of leaking.
+	server := newBulkServer(1024)
+	defer server.Close()
+	pagesChan := make(chan *giphy.Page)
+	cancelChan := make(chan bool)
+	stopped := make(chan bool)
+	go func() {
+		defer close(stopped)
+		defer close(pagesChan)
+		pagesChan <- &giphy.Page{Giphs: []*giphy.Giph{server.giph("partial")}, Err: pageErr}
+		for {
+			select {
+			case <-cancelChan:
+				return
+			default:
+			}
+			pagesChan <- &giphy.Page{}
+		}
+	}()
+	pager = &giphy.ResponsePager{Pages: pagesChan, Cancel: func() error { close(cancelChan); return nil }}
+	dir := t.TempDir()
+	summary, err := client.BulkDownload(context.Background(), pager, &giphy.BulkOptions{Dir: dir})
+	if err != pageErr {
+		t.Errorf("gotErr: %v wantErr: %v", err, pageErr)
+	}
+	if summary == nil || summary.Completed != 1 {
+		t.Errorf("gotSummary: %+v want the giph of the failed page completed", summary)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Error("the pager was left blocked on sending a page")
+	}
+	if _, err := client.BulkDownload(context.Background(), pagerOf(), nil); err == nil {
+		t.Error("expected an error without a Dir")
+	}
+}
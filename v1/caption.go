@@ -0,0 +1,443 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sort"
+	"strings"
+
+	"go.opencensus.io/trace"
+)
+
+type CaptionOptions struct {
+	// Rendition is the one captioned, defaulting to RenditionOriginal.
+	Rendition Rendition `json:"rendition,omitempty"`
+
+	// Top and Bottom are the captions, wrapped at spaces to fit
+	// the width of the GIF. At least one of them must be set. The
+	// embedded font only covers printable ASCII, so captions with
+	// other runes fail with an *UnsupportedRunesError.
+	Top    string `json:"top,omitempty"`
+	Bottom string `json:"bottom,omitempty"`
+
+	// Scale is the number of pixels per pixel of the embedded font.
+	// It defaults to the largest that keeps each caption within a
+	// third of the height of the GIF.
+	Scale int `json:"scale,omitempty"`
+
+	// Color defaults to white and Outline to black. OutlineWidth
+	// defaults to half the Scale, rounded up.
+	Color        color.Color `json:"-"`
+	Outline      color.Color `json:"-"`
+	OutlineWidth int         `json:"outline_width,omitempty"`
+
+	// Dither diffuses the error of the re-quantized colors, which
+	// smooths gradients at the cost of some flicker between frames.
+	Dither bool `json:"dither,omitempty"`
+}
+
+var errNoCaption = errors.New("expecting a Top or Bottom caption")
+
+func (opts *CaptionOptions) validate() error {
+	if opts == nil || (strings.TrimSpace(opts.Top) == "" && strings.TrimSpace(opts.Bottom) == "") {
+		return errNoCaption
+	}
+	return checkDrawable(opts.Top, opts.Bottom)
+}
+
+// captionLayout is where the captions are drawn on every frame.
+type captionLayout struct {
+	scale, outline int
+	lines          []string
+	origins        []image.Point
+	fill, stroke   color.Color
+}
+
+func (opts *CaptionOptions) layout(width, height int) *captionLayout {
+	cl := &captionLayout{fill: opts.Color, stroke: opts.Outline}
+	if cl.fill == nil {
+		cl.fill = color.White
+	}
+	if cl.stroke == nil {
+		cl.stroke = color.Black
+	}
+
+	// Try the largest scale that fits, unless one was set.
+	scales := []int{opts.Scale}
+	if opts.Scale <= 0 {
+		scales = scales[:0]
+		for scale := max(height/(glyphHeight*4), 1); scale >= 1; scale-- {
+			scales = append(scales, scale)
+		}
+	}
+	var top, bottom []string
+	for _, scale := range scales {
+		cl.scale = scale
+		cl.outline = opts.OutlineWidth
+		if cl.outline <= 0 {
+			cl.outline = (scale + 1) / 2
+		}
+		available := width - 2*cl.margin()
+		top = wrapText(opts.Top, available, scale)
+		bottom = wrapText(opts.Bottom, available, scale)
+		if max(len(top), len(bottom))*cl.lineHeight() <= height/3 {
+			break
+		}
+	}
+
+	for i, line := range top {
+		cl.add(line, cl.margin()+i*cl.lineHeight(), width)
+	}
+	bottomY := height - cl.margin() - len(bottom)*cl.lineHeight()
+	for i, line := range bottom {
+		cl.add(line, bottomY+i*cl.lineHeight(), width)
+	}
+	return cl
+}
+
+func (cl *captionLayout) margin() int {
+	return cl.scale + cl.outline
+}
+
+func (cl *captionLayout) lineHeight() int {
+	return glyphAdvanceY*cl.scale + 2*cl.outline
+}
+
+// add centers line horizontally with the top of its outline at y.
+func (cl *captionLayout) add(line string, y, width int) {
+	size := textSize(line, cl.scale)
+	cl.lines = append(cl.lines, line)
+	cl.origins = append(cl.origins, image.Pt((width-size.X)/2, y+cl.outline))
+}
+
+// render draws the outlined captions once onto a transparent overlay
+// the size of bounds, returning it along with the part that they cover.
+func (cl *captionLayout) render(bounds image.Rectangle) (*image.RGBA, image.Rectangle) {
+	fill := image.NewAlpha(bounds)
+	var covered image.Rectangle
+	for i, line := range cl.lines {
+		drawText(fill, cl.origins[i], line, cl.scale, color.Opaque)
+		size := textSize(line, cl.scale)
+		covered = covered.Union(image.Rectangle{Min: cl.origins[i], Max: cl.origins[i].Add(size)}.Inset(-cl.outline))
+	}
+	covered = covered.Intersect(bounds)
+	stroke := dilate(fill, covered, cl.outline)
+
+	overlay := image.NewRGBA(bounds)
+	fillColor := color.RGBAModel.Convert(cl.fill).(color.RGBA)
+	strokeColor := color.RGBAModel.Convert(cl.stroke).(color.RGBA)
+	for y := covered.Min.Y; y < covered.Max.Y; y++ {
+		for x := covered.Min.X; x < covered.Max.X; x++ {
+			var c color.RGBA
+			switch {
+			case fill.AlphaAt(x, y).A != 0:
+				c = fillColor
+			case stroke.AlphaAt(x, y).A != 0:
+				c = strokeColor
+			default:
+				continue
+			}
+			overlay.SetRGBA(x, y, c)
+		}
+	}
+	return overlay, covered
+}
+
+// dilate grows the opaque pixels of mask within r by radius
+// in every direction, first along the rows then the columns.
+func dilate(mask *image.Alpha, r image.Rectangle, radius int) *image.Alpha {
+	rows := image.NewAlpha(mask.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if mask.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			for dx := max(x-radius, r.Min.X); dx <= min(x+radius, r.Max.X-1); dx++ {
+				rows.SetAlpha(dx, y, color.Alpha{A: 0xff})
+			}
+		}
+	}
+	dilated := image.NewAlpha(mask.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if rows.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			for dy := max(y-radius, r.Min.Y); dy <= min(y+radius, r.Max.Y-1); dy++ {
+				dilated.SetAlpha(x, dy, color.Alpha{A: 0xff})
+			}
+		}
+	}
+	return dilated
+}
+
+// wrapText breaks text at spaces into lines that are at most width
+// pixels wide at scale, splitting the words that are wider than that.
+func wrapText(text string, width, scale int) []string {
+	perLine := max((width+scale)/(glyphAdvanceX*scale), 1)
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(text) {
+		runes := []rune(word)
+		for len(runes) > perLine {
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			lines = append(lines, string(runes[:perLine]))
+			runes = runes[perLine:]
+		}
+		switch {
+		case len(runes) == 0:
+		case len(line) == 0:
+			line = runes
+		case len(line)+1+len(runes) <= perLine:
+			line = append(append(line, ' '), runes...)
+		default:
+			lines = append(lines, string(line))
+			line = runes
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// AddCaptions draws outlined captions on every frame of g, returning a new
+// GIF with the same frame delays, disposal methods and loop count. As
+// the captions may be drawn in colors absent from a frame's palette,
+// every frame is composited in full and re-quantized to a palette of
+// its own that includes the caption colors.
+func AddCaptions(g *gif.GIF, opts *CaptionOptions) (*gif.GIF, error) {
+	if g == nil || len(g.Image) == 0 {
+		return nil, errNoFrames
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	captioned := &gif.GIF{
+		Delay:           append([]int(nil), g.Delay...),
+		Disposal:        append([]byte(nil), g.Disposal...),
+		LoopCount:       g.LoopCount,
+		BackgroundIndex: g.BackgroundIndex,
+	}
+	var layout *captionLayout
+	var overlay *image.RGBA
+	var covered image.Rectangle
+	compositeFrames(g, func(i int, canvas *image.RGBA) bool {
+		if layout == nil {
+			// The captions are the same on every frame, so render them once.
+			layout = opts.layout(canvas.Rect.Dx(), canvas.Rect.Dy())
+			overlay, covered = layout.render(canvas.Rect)
+			// Every frame carries its own palette, so there is no ColorModel.
+			captioned.Config = image.Config{Width: canvas.Rect.Dx(), Height: canvas.Rect.Dy()}
+		}
+		frame := cloneRGBA(canvas)
+		draw.Draw(frame, covered, overlay, covered.Min, draw.Over)
+		captioned.Image = append(captioned.Image, quantize(frame, []color.Color{layout.fill, layout.stroke}, opts.Dither))
+		return true
+	})
+	return captioned, nil
+}
+
+// quantize maps img onto a palette of at most 256 colors: a transparent
+// one if img has transparent pixels, the reserved colors and the median
+// cut of the colors of img.
+func quantize(img *image.RGBA, reserved []color.Color, dither bool) *image.Paletted {
+	var p color.Palette
+	transparent := false
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] < 0x80 {
+			p, transparent = append(p, color.RGBA{}), true
+			break
+		}
+	}
+	for _, c := range reserved {
+		p = append(p, color.RGBAModel.Convert(c))
+	}
+	p = append(p, medianCut(img, 256-len(p))...)
+
+	paletted := image.NewPaletted(img.Rect, p)
+	if dither {
+		draw.FloydSteinberg.Draw(paletted, img.Rect, img, img.Rect.Min)
+		return paletted
+	}
+
+	// Palette.Index searches the whole palette, so
+	// remember the index of every distinct color.
+	indices := make(map[[4]uint8]uint8)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			offset := img.PixOffset(x, y)
+			var px [4]uint8
+			copy(px[:], img.Pix[offset:offset+4])
+			if transparent && px[3] < 0x80 {
+				paletted.Pix[paletted.PixOffset(x, y)] = 0
+				continue
+			}
+			index, ok := indices[px]
+			if !ok {
+				index = uint8(p.Index(color.RGBA{R: px[0], G: px[1], B: px[2], A: px[3]}))
+				indices[px] = index
+			}
+			paletted.Pix[paletted.PixOffset(x, y)] = index
+		}
+	}
+	return paletted
+}
+
+// colorBox is a set of the colors of an image, weighted by how often
+// they occur, that median cut splits until there are enough boxes.
+type colorBox struct {
+	colors []weightedColor
+	weight int
+}
+
+type weightedColor struct {
+	rgb    [3]uint8
+	weight int
+}
+
+// longest returns the channel with the widest range in cb and that range.
+func (cb *colorBox) longest() (channel, spread int) {
+	for c := 0; c < 3; c++ {
+		lo, hi := uint8(255), uint8(0)
+		for _, wc := range cb.colors {
+			lo, hi = min(lo, wc.rgb[c]), max(hi, wc.rgb[c])
+		}
+		if int(hi)-int(lo) > spread {
+			channel, spread = c, int(hi)-int(lo)
+		}
+	}
+	return channel, spread
+}
+
+// split divides cb at the weighted median of its widest channel.
+func (cb *colorBox) split() (*colorBox, *colorBox) {
+	channel, _ := cb.longest()
+	sort.SliceStable(cb.colors, func(i, j int) bool { return cb.colors[i].rgb[channel] < cb.colors[j].rgb[channel] })
+	half, at := 0, 0
+	for at < len(cb.colors)-1 && half+cb.colors[at].weight <= cb.weight/2 {
+		half += cb.colors[at].weight
+		at++
+	}
+	at = max(at, 1)
+	low := &colorBox{colors: cb.colors[:at]}
+	high := &colorBox{colors: cb.colors[at:]}
+	for _, wc := range low.colors {
+		low.weight += wc.weight
+	}
+	high.weight = cb.weight - low.weight
+	return low, high
+}
+
+func (cb *colorBox) average() color.RGBA {
+	var sums [3]int
+	for _, wc := range cb.colors {
+		for c := range sums {
+			sums[c] += int(wc.rgb[c]) * wc.weight
+		}
+	}
+	return color.RGBA{
+		R: uint8((sums[0] + cb.weight/2) / cb.weight),
+		G: uint8((sums[1] + cb.weight/2) / cb.weight),
+		B: uint8((sums[2] + cb.weight/2) / cb.weight),
+		A: 0xff,
+	}
+}
+
+// medianCut picks up to n colors representative of the opaque pixels of img.
+func medianCut(img *image.RGBA, n int) color.Palette {
+	counts := make(map[[3]uint8]int)
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i+3] >= 0x80 {
+			counts[[3]uint8{img.Pix[i], img.Pix[i+1], img.Pix[i+2]}]++
+		}
+	}
+	if len(counts) == 0 || n <= 0 {
+		return nil
+	}
+
+	root := new(colorBox)
+	for rgb, count := range counts {
+		root.colors = append(root.colors, weightedColor{rgb: rgb, weight: count})
+		root.weight += count
+	}
+	// Start from a set order for the palette not to vary between runs.
+	sort.Slice(root.colors, func(i, j int) bool {
+		a, b := root.colors[i].rgb, root.colors[j].rgb
+		return a[0] < b[0] || (a[0] == b[0] && (a[1] < b[1] || (a[1] == b[1] && a[2] < b[2])))
+	})
+	boxes := []*colorBox{root}
+	for len(boxes) < n {
+		// Split the box whose colors differ the most,
+		// weighing in how much of the image it covers.
+		best, bestScore := -1, 0
+		for i, box := range boxes {
+			if len(box.colors) < 2 {
+				continue
+			}
+			_, spread := box.longest()
+			if score := spread * box.weight; score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+		low, high := boxes[best].split()
+		boxes[best] = low
+		boxes = append(boxes, high)
+	}
+
+	p := make(color.Palette, len(boxes))
+	for i, box := range boxes {
+		p[i] = box.average()
+	}
+	return p
+}
+
+// AddCaptions downloads the rendition of giph chosen by opts, captions
+// it and writes the resulting animated GIF to w.
+func (c *Client) AddCaptions(ctx context.Context, giph *Giph, w io.Writer, opts *CaptionOptions) error {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).AddCaptions")
+	defer span.End()
+
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	r := opts.Rendition
+	if r == "" {
+		r = RenditionOriginal
+	}
+	g, err := c.decodeRendition(ctx, giph, r)
+	if err != nil {
+		return err
+	}
+	captioned, err := AddCaptions(g, opts)
+	if err != nil {
+		return err
+	}
+	return gif.EncodeAll(w, captioned)
+}
@@ -0,0 +1,268 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+// countColor counts the pixels of c in the rows from y0 to y1 of img.
+func countColor(img image.Image, y0, y1 int, c color.RGBA) int {
+	n := 0
+	for y := y0; y < y1; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			if rgbaAt(img, x, y) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestAddCaptions(t *testing.T) {
+	black := color.RGBA{A: 0xff}
+	yellow := color.RGBA{R: 0xff, G: 0xff, A: 0xff}
+	original := sceneGIF(scene(120, 90, false, 0), scene(120, 90, true, 0), scene(120, 90, false, 0))
+	original.Delay = []int{10, 20, 30}
+	original.Disposal = []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalPrevious}
+	original.LoopCount = 3
+
+	sources := []image.Image{scene(120, 90, false, 0), scene(120, 90, true, 0), scene(120, 90, false, 0)}
+
+	tests := [...]struct {
+		opts *giphy.CaptionOptions
+		// captions are the rows that the captions are drawn in,
+		// and untouched the rows that must be left as they were.
+		captions  [][2]int
+		untouched [2]int
+		fill      color.RGBA
+	}{
+		0: {
+			opts:      &giphy.CaptionOptions{Top: "HELLO", Bottom: "world"},
+			captions:  [][2]int{{0, 30}, {60, 90}},
+			untouched: [2]int{30, 60},
+			fill:      white,
+		},
+		1: {
+			opts:      &giphy.CaptionOptions{Bottom: "Such caption", Color: yellow, Scale: 1},
+			captions:  [][2]int{{75, 90}},
+			untouched: [2]int{0, 75},
+			fill:      yellow,
+		},
+		2: {
+			// Too long for a line at any scale, the word is split.
+			opts:      &giphy.CaptionOptions{Bottom: "supercalifragilisticexpialidocious"},
+			captions:  [][2]int{{60, 75}, {75, 90}},
+			untouched: [2]int{0, 60},
+			fill:      white,
+		},
+	}
+
+	for i, tt := range tests {
+		captioned, err := giphy.AddCaptions(original, tt.opts)
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(captioned.Delay, original.Delay) || !reflect.DeepEqual(captioned.Disposal, original.Disposal) {
+			t.Errorf("#%d: got delays %v and disposals %v", i, captioned.Delay, captioned.Disposal)
+		}
+		if captioned.LoopCount != original.LoopCount {
+			t.Errorf("#%d: got loop count %d", i, captioned.LoopCount)
+		}
+
+		// Encoding and decoding checks that every frame's own palette holds up.
+		buf := new(bytes.Buffer)
+		if err := gif.EncodeAll(buf, captioned); err != nil {
+			t.Errorf("#%d: encoding: %v", i, err)
+			continue
+		}
+		decoded, err := gif.DecodeAll(buf)
+		if err != nil {
+			t.Errorf("#%d: decoding: %v", i, err)
+			continue
+		}
+		if len(decoded.Image) != len(sources) {
+			t.Errorf("#%d: got %d frames want %d", i, len(decoded.Image), len(sources))
+			continue
+		}
+
+		for j, frame := range decoded.Image {
+			source := sources[j]
+			for _, rows := range tt.captions {
+				if countColor(frame, rows[0], rows[1], tt.fill) <= countColor(source, rows[0], rows[1], tt.fill) ||
+					countColor(frame, rows[0], rows[1], black) <= countColor(source, rows[0], rows[1], black) {
+					t.Errorf("#%d: frame #%d: no outlined caption in rows %v", i, j, rows)
+				}
+			}
+			// The scenes have few enough colors to be re-quantized exactly.
+			for y := tt.untouched[0]; y < tt.untouched[1]; y++ {
+				for x := 0; x < 120; x++ {
+					if got, want := rgbaAt(frame, x, y), rgbaAt(source, x, y); got != want {
+						t.Fatalf("#%d: frame #%d: at (%d, %d): got %v want %v", i, j, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+
+	if _, err := giphy.AddCaptions(original, &giphy.CaptionOptions{Top: "  "}); err == nil {
+		t.Error("expected an error without captions")
+	}
+	if _, err := giphy.AddCaptions(&gif.GIF{}, &giphy.CaptionOptions{Top: "hi"}); err == nil {
+		t.Error("expected an error for a GIF without frames")
+	}
+}
+
+func TestAddCaptionsTransparency(t *testing.T) {
+	// The second frame clears the first, so the right half of
+	// the screen is transparent while it is displayed.
+	full := image.Rect(0, 0, 80, 60)
+	g := &gif.GIF{
+		Image:    []*image.Paletted{solidFrame(full, red), solidFrame(image.Rect(0, 0, 40, 60), blue)},
+		Delay:    []int{10, 10},
+		Disposal: []byte{gif.DisposalBackground, gif.DisposalNone},
+		Config:   image.Config{Width: 80, Height: 60},
+	}
+	captioned, err := giphy.AddCaptions(g, &giphy.CaptionOptions{Top: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := captioned.Image[1]
+	if got := rgbaAt(second, 70, 50); got.A != 0 {
+		t.Errorf("got %v want the cleared area to stay transparent", got)
+	}
+	if got := rgbaAt(second, 10, 50); got != blue {
+		t.Errorf("got %v want %v", got, blue)
+	}
+	if got := rgbaAt(captioned.Image[0], 70, 50); got != red {
+		t.Errorf("got %v want %v", got, red)
+	}
+}
+
+func TestClientAddCaptions(t *testing.T) {
+	server := newPathServer(map[string][]byte{"/anim.gif": encodeGIF(t, storyboardGIF())})
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	giph := &giphy.Giph{ID: "storyboard", Sizes: map[string]*giphy.GIF{
+		"fixed_width": {URL: server.URL + "/anim.gif"},
+	}}
+
+	buf := new(bytes.Buffer)
+	opts := &giphy.CaptionOptions{Rendition: giphy.RenditionFixedWidth, Top: "top", Bottom: "bottom", Dither: true}
+	if err := client.AddCaptions(context.Background(), giph, buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	info, err := giphy.InspectGIF(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FrameCount != 5 || info.Width != 40 || info.Height != 30 {
+		t.Errorf("got %+v", info)
+	}
+
+	if err := client.AddCaptions(context.Background(), giph, buf, &giphy.CaptionOptions{Rendition: giphy.RenditionFixedWidth}); err == nil {
+		t.Error("expected an error without captions")
+	}
+	if got := server.takeRequested(); len(got) != 1 {
+		t.Errorf("got requests %q want just the one download", got)
+	}
+	if err := client.AddCaptions(context.Background(), giph, buf, &giphy.CaptionOptions{Top: "missing"}); err == nil {
+		t.Error("expected an error for a missing rendition")
+	}
+}
+
+func TestAddCaptionsUnsupportedRunes(t *testing.T) {
+	tests := [...]struct {
+		opts      *giphy.CaptionOptions
+		wantRunes []rune
+	}{
+		0: {opts: &giphy.CaptionOptions{Top: "plain ASCII, with tabs\tand ~symbols!"}},
+		1: {opts: &giphy.CaptionOptions{Top: "café", Bottom: "crème brûlée"}, wantRunes: []rune{'é', 'è', 'û'}},
+		2: {opts: &giphy.CaptionOptions{Bottom: "привет 🎉"}, wantRunes: []rune{'п', 'р', 'и', 'в', 'е', 'т', '🎉'}},
+		3: {opts: &giphy.CaptionOptions{Top: "שלום"}, wantRunes: []rune{'ש', 'ל', 'ו', 'ם'}},
+	}
+
+	for i, tt := range tests {
+		_, err := giphy.AddCaptions(storyboardGIF(), tt.opts)
+		if tt.wantRunes == nil {
+			if err != nil {
+				t.Errorf("#%d: unexpected err: %v", i, err)
+			}
+			continue
+		}
+		var ure *giphy.UnsupportedRunesError
+		if !errors.As(err, &ure) {
+			t.Errorf("#%d: gotErr: %v want an *UnsupportedRunesError", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(ure.Runes, tt.wantRunes) {
+			t.Errorf("#%d: gotRunes: %q wantRunes: %q", i, ure.Runes, tt.wantRunes)
+		}
+		if !strings.Contains(err.Error(), string(tt.wantRunes[0])) {
+			t.Errorf("#%d: %q does not name %q", i, err, tt.wantRunes[0])
+		}
+	}
+
+	// The captions are checked before downloading anything.
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&failingTransport{t: t})
+	giph := &giphy.Giph{ID: "unsupported", Sizes: map[string]*giphy.GIF{"original": {URL: "https://media.giphy.com/x.gif"}}}
+	var ure *giphy.UnsupportedRunesError
+	if err := client.AddCaptions(context.Background(), giph, new(bytes.Buffer), &giphy.CaptionOptions{Top: "¡hola!"}); !errors.As(err, &ure) {
+		t.Errorf("gotErr: %v want an *UnsupportedRunesError", err)
+	}
+}
+
+func TestAddCaptionsOutline(t *testing.T) {
+	frames := make([]*image.Paletted, 40)
+	for i := range frames {
+		frames[i] = solidFrame(image.Rect(0, 0, 60, 60), blue)
+	}
+	g := &gif.GIF{Image: frames, Delay: make([]int, len(frames)), Config: image.Config{Width: 60, Height: 60}}
+	captioned, err := giphy.AddCaptions(g, &giphy.CaptionOptions{Top: "I", Scale: 1, OutlineWidth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The stem of the "I" is the column x=29 from y=5 to 11, so along
+	// y=8 the outline spans two pixels on either side of it.
+	black := color.RGBA{A: 0xff}
+	want := map[int]color.RGBA{26: blue, 27: black, 28: black, 29: white, 30: black, 31: black, 32: blue}
+	for i, frame := range captioned.Image {
+		for x, c := range want {
+			if got := rgbaAt(frame, x, 8); got != c {
+				t.Fatalf("frame #%d: at (%d, 8): got %v want %v", i, x, got, c)
+			}
+		}
+	}
+}
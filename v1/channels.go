@@ -0,0 +1,155 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.opencensus.io/trace"
+)
+
+// User is the profile of the creator of a Giph or the owner of a Channel.
+type User struct {
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	BannerURL   string `json:"banner_url,omitempty"`
+	BannerImage string `json:"banner_image,omitempty"`
+	ProfileURL  string `json:"profile_url,omitempty"`
+
+	Twitter      string `json:"twitter,omitempty"`
+	InstagramURL string `json:"instagram_url,omitempty"`
+	WebsiteURL   string `json:"website_url,omitempty"`
+
+	IsVerified bool `json:"is_verified,omitempty"`
+}
+
+type Channel struct {
+	ID               int64  `json:"id,omitempty"`
+	URL              string `json:"url,omitempty"`
+	Slug             string `json:"slug,omitempty"`
+	Type             string `json:"type,omitempty"`
+	ContentType      string `json:"content_type,omitempty"`
+	DisplayName      string `json:"display_name,omitempty"`
+	ShortDisplayName string `json:"short_display_name,omitempty"`
+	Description      string `json:"description,omitempty"`
+	BannerImage      string `json:"banner_image,omitempty"`
+	HasChildren      bool   `json:"has_children,omitempty"`
+	IsVisible        bool   `json:"is_visible,omitempty"`
+
+	User        *User `json:"user,omitempty"`
+	FeaturedGIF *Giph `json:"featured_gif,omitempty"`
+}
+
+type ChannelPager struct {
+	Cancel func() error        `json:"-"`
+	Pages  <-chan *ChannelPage `json:"-"`
+}
+
+type ChannelPage struct {
+	Channels []*Channel `json:"channels"`
+	Err      error      `json:"error"`
+
+	// Warnings are only set in strict decoding mode.
+	Warnings []*DecodeWarning `json:"warnings,omitempty"`
+
+	Meta       *Meta       `json:"meta,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+
+	PageNumber uint64 `json:"page_number"`
+}
+
+type channelsResponse struct {
+	Channels   []*Channel  `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Meta       *Meta       `json:"meta,omitempty"`
+}
+
+func (c *Client) SearchChannels(ctx context.Context, req *Request) (*ChannelPager, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).SearchChannels")
+	defer span.End()
+
+	if err := req.Validate(EndpointSearchChannels); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		req = new(Request)
+	}
+	req = c.withSafety(req)
+	safety := c.safetyPolicy()
+
+	cancelChan, cancelFn := makeCanceler()
+	pagesChan := make(chan *ChannelPage, 1)
+
+	go func() {
+		defer close(pagesChan)
+
+		c.paginate(ctx, req, cancelChan, func(pageNumber, offset uint64) (*Pagination, bool) {
+			page := &ChannelPage{PageNumber: pageNumber}
+			res := new(channelsResponse)
+			warnings, err := c.getPage(ctx, string(EndpointSearchChannels), req.pager(offset), res)
+			page.Warnings = warnings
+			if err != nil {
+				page.Err = err
+				pagesChan <- page
+				return nil, false
+			}
+
+			if len(res.Channels) == 0 {
+				return nil, false
+			}
+			if safety != nil {
+				for _, channel := range res.Channels {
+					if channel.FeaturedGIF != nil && !safety.allows(channel.FeaturedGIF) {
+						channel.FeaturedGIF = nil
+					}
+				}
+			}
+			page.Channels = res.Channels
+			page.Meta = res.Meta
+			page.Pagination = res.Pagination
+			pagesChan <- page
+			return res.Pagination, true
+		})
+	}()
+
+	return &ChannelPager{Pages: pagesChan, Cancel: cancelFn}, nil
+}
+
+var errBlankUsername = errors.New("expecting a non-blank username")
+
+// ChannelGIFs pages through the GIFs uploaded by the channel owned by username.
+// req.Query, if set, narrows the results down to that channel's matches.
+func (c *Client) ChannelGIFs(ctx context.Context, username string, req *Request) (*ResponsePager, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).ChannelGIFs")
+	defer span.End()
+
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if username == "" {
+		return nil, errBlankUsername
+	}
+
+	// GIPHY scopes a search to a channel when the query is prefixed with "@username".
+	var channelReq Request
+	if req != nil {
+		channelReq = *req
+	}
+	channelReq.Query = strings.TrimSpace("@" + username + " " + channelReq.Query)
+	return c.fetch(ctx, &channelReq, EndpointSearch)
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+// Video is the rendition metadata that GIPHY Clips carry,
+// in addition to the usual images of a Giph.
+type Video struct {
+	Assets   map[string]*VideoAsset `json:"assets,omitempty"`
+	Captions map[string]*Caption    `json:"captions,omitempty"`
+
+	// Duration is the length of the clip in seconds.
+	Duration float64 `json:"duration,omitempty"`
+
+	Description  string `json:"description,omitempty"`
+	DashManifest string `json:"dash_manifest,omitempty"`
+	HLSManifest  string `json:"hls_manifest,omitempty"`
+}
+
+type VideoAsset struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,string,omitempty"`
+	Height int    `json:"height,string,omitempty"`
+}
+
+type Caption struct {
+	SRT string `json:"srt,omitempty"`
+	VTT string `json:"vtt,omitempty"`
+}
+
+// Video resolutions that GIPHY returns as keys of Video.Assets.
+const (
+	Resolution360p  = "360p"
+	Resolution480p  = "480p"
+	Resolution720p  = "720p"
+	Resolution1080p = "1080p"
+	Resolution4K    = "4k"
+)
+
+func (v *Video) Length() time.Duration {
+	if v == nil {
+		return 0
+	}
+	return time.Duration(v.Duration * float64(time.Second))
+}
+
+func (c *Client) SearchClips(ctx context.Context, req *Request) (*ResponsePager, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).SearchClips")
+	defer span.End()
+
+	return c.fetch(ctx, req, EndpointSearchClips)
+}
+
+func (c *Client) TrendingClips(ctx context.Context, req *Request) (*ResponsePager, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).TrendingClips")
+	defer span.End()
+
+	return c.fetch(ctx, req, EndpointTrendingClips)
+}
+
+func (c *Client) ClipByID(ctx context.Context, id string) (*Giph, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).ClipByID")
+	defer span.End()
+
+	qv := make(url.Values)
+	qv.Set("api_key", c._apiKey())
+	theURL := fmt.Sprintf("%s/clips/%s?%s", baseURL, id, qv.Encode())
+	return c.fetchGIF(ctx, theURL)
+}
@@ -0,0 +1,106 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"sync"
+)
+
+// SeenSet records the results that were already delivered, so that
+// deduplication can span several calls when the same set is reused.
+// Implementations must be safe for concurrent use.
+type SeenSet interface {
+	// CheckAndAdd records key and reports whether it was already recorded.
+	CheckAndAdd(key string) bool
+}
+
+type memorySeenSet struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// NewSeenSet returns an in-memory SeenSet.
+func NewSeenSet() SeenSet {
+	return &memorySeenSet{keys: make(map[string]bool)}
+}
+
+func (ms *memorySeenSet) CheckAndAdd(key string) bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.keys[key] {
+		return true
+	}
+	ms.keys[key] = true
+	return false
+}
+
+type Dedupe struct {
+	// ByID drops Giphs whose ID was already seen.
+	ByID bool `json:"by_id"`
+
+	// ByMedia drops Giphs whose original media was already seen,
+	// as told by its content hash or, failing that, its URL.
+	// Different IDs at times carry the very same media.
+	ByMedia bool `json:"by_media"`
+
+	// Seen, if set, is shared with other calls. Otherwise each call
+	// gets its own, or, for Session calls, the Session's.
+	Seen SeenSet `json:"-"`
+}
+
+func (d *Dedupe) seenSet() SeenSet {
+	if d.Seen != nil {
+		return d.Seen
+	}
+	return NewSeenSet()
+}
+
+// isDuplicate records giph in seen, reporting whether it was already there.
+func (d *Dedupe) isDuplicate(seen SeenSet, giph *Giph) bool {
+	duplicate := false
+	if d.ByID && giph.ID != "" {
+		duplicate = seen.CheckAndAdd("id:" + giph.ID)
+	}
+	if d.ByMedia {
+		if key := giph.mediaKey(); key != "" && seen.CheckAndAdd("media:"+key) {
+			duplicate = true
+		}
+	}
+	return duplicate
+}
+
+// mediaKey identifies the content of the original rendition of g.
+func (g *Giph) mediaKey() string {
+	original := g.Rendition(RenditionOriginal)
+	if original == nil {
+		return ""
+	}
+	if original.Hash != "" {
+		return original.Hash
+	}
+	u, err := url.Parse(original.URL)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	// The same file is served off several hosts, such as media0 to
+	// media4.giphy.com, and with tracking query values, so only the
+	// path is telling.
+	sum := sha1.Sum([]byte(u.Path))
+	return hex.EncodeToString(sum[:])
+}
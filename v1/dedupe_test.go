@@ -0,0 +1,198 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func drainGiphs(t *testing.T, res *giphy.ResponsePager) (giphs []*giphy.Giph, duplicates int) {
+	for page := range res.Pages {
+		if page.Err != nil {
+			t.Fatalf("Page #%d err: %v", page.PageNumber, page.Err)
+		}
+		giphs = append(giphs, page.Giphs...)
+		if page.Filtered != nil {
+			duplicates += page.Filtered.Duplicates
+		}
+	}
+	return giphs, duplicates
+}
+
+func TestDedupe(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchRoute})
+
+	// The 100 fixture Giphs repeat "yh5OPlk4y0x5C" on two pages, and
+	// "3ohzdV9cCVSLlblXgc" and "xUA7ba5GJ8tSdFwCPu" share their media.
+	tests := [...]struct {
+		dedupe         *giphy.Dedupe
+		wantGiphs      int
+		wantDuplicates int
+	}{
+		0: {dedupe: nil, wantGiphs: 100},
+		1: {dedupe: &giphy.Dedupe{ByID: true}, wantGiphs: 99, wantDuplicates: 1},
+		2: {dedupe: &giphy.Dedupe{ByMedia: true}, wantGiphs: 98, wantDuplicates: 2},
+		3: {dedupe: &giphy.Dedupe{ByID: true, ByMedia: true}, wantGiphs: 98, wantDuplicates: 2},
+	}
+
+	for i, tt := range tests {
+		res, err := client.Search(context.Background(), &giphy.Request{
+			Query:              "hip hop",
+			ThrottleDurationMs: giphy.NoThrottle,
+			Dedupe:             tt.dedupe,
+		})
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		giphs, duplicates := drainGiphs(t, res)
+		if len(giphs) != tt.wantGiphs || duplicates != tt.wantDuplicates {
+			t.Errorf("#%d: got %d giphs, %d duplicates want %d giphs, %d duplicates",
+				i, len(giphs), duplicates, tt.wantGiphs, tt.wantDuplicates)
+		}
+	}
+}
+
+type countingSeenSet struct {
+	mu   sync.Mutex
+	keys map[string]int
+}
+
+func (cs *countingSeenSet) CheckAndAdd(key string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.keys[key]++
+	return cs.keys[key] > 1
+}
+
+func TestDedupeAcrossCalls(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchRoute})
+
+	seen := &countingSeenSet{keys: make(map[string]int)}
+	req := &giphy.Request{
+		Query:              "hip hop",
+		MaxPageNumber:      1,
+		ThrottleDurationMs: giphy.NoThrottle,
+		Dedupe:             &giphy.Dedupe{ByID: true, Seen: seen},
+	}
+	first, _ := searchAll(t, client.Search, req)
+	if len(first) != 25 {
+		t.Fatalf("first call: got %d giphs want 25", len(first))
+	}
+	// The first page was already seen, so the
+	// second call moves on to the pages after it.
+	second, duplicates := searchAll(t, client.Search, req)
+	if len(second) != 25 || duplicates != 25 {
+		t.Errorf("second call: got %d giphs, %d duplicates want 25 and 25", len(second), duplicates)
+	}
+	for _, giph := range second {
+		if seen.keys["id:"+giph.ID] != 1 {
+			t.Errorf("%q was delivered again", giph.ID)
+		}
+	}
+
+	// Sessions share a SeenSet between their calls by default.
+	session, err := client.ResumeSession(testRandomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sreq := &giphy.Request{Query: "hip hop", MaxPageNumber: 1, ThrottleDurationMs: giphy.NoThrottle, Dedupe: &giphy.Dedupe{ByID: true}}
+	sessionFirst, _ := searchAll(t, session.Search, sreq)
+	sessionSecond, _ := searchAll(t, session.Search, sreq)
+	for _, giph := range sessionSecond {
+		for _, prev := range sessionFirst {
+			if giph.ID == prev.ID {
+				t.Errorf("%q was delivered in both session calls", giph.ID)
+			}
+		}
+	}
+	if sreq.Dedupe.Seen != nil {
+		t.Error("the session must not modify the caller's Dedupe")
+	}
+}
+
+func searchAll(t *testing.T, search func(context.Context, *giphy.Request) (*giphy.ResponsePager, error), req *giphy.Request) ([]*giphy.Giph, int) {
+	res, err := search(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return drainGiphs(t, res)
+}
+
+// firstPageTransport serves giphs with the given IDs at offset 0 and
+// no more results after them.
+type firstPageTransport struct {
+	ids []string
+}
+
+func (fp *firstPageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var data []string
+	if offset := req.URL.Query().Get("offset"); offset == "" || offset == "0" {
+		for _, id := range fp.ids {
+			data = append(data, fmt.Sprintf(`{"id": %q}`, id))
+		}
+	}
+	body := `{"data": [` + strings.Join(data, ", ") + `], "pagination": {"count": ` + strconv.Itoa(len(data)) + `}}`
+	return makeResp("200 OK", http.StatusOK, ioutil.NopCloser(strings.NewReader(body))), nil
+}
+
+func TestDedupeLeftoverNotSeen(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&firstPageTransport{ids: []string{"a", "b", "c", "d"}})
+
+	req := &giphy.Request{
+		LimitPerPage:       2,
+		MaxPageNumber:      1,
+		ThrottleDurationMs: giphy.NoThrottle,
+		Dedupe:             &giphy.Dedupe{ByID: true, Seen: giphy.NewSeenSet()},
+	}
+	// "c" and "d" are fetched but left over by the first
+	// call, so the second call must still deliver them.
+	wantCalls := [...][]string{
+		0: {"a", "b"},
+		1: {"c", "d"},
+	}
+	for i, want := range wantCalls {
+		giphs, _ := searchAll(t, client.Trending, req)
+		var got []string
+		for _, giph := range giphs {
+			got = append(got, giph.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("call #%d: gotIDs: %q wantIDs: %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DetectLanguage infers the supported Language that text is most likely
+// written in. It first looks at the Unicode scripts of text and, for Latin
+// script, then scores text against trigram profiles of each language.
+// It returns false if text carries too little evidence to decide.
+func DetectLanguage(text string) (Language, bool) {
+	counts := make(map[*unicode.RangeTable]int)
+	total := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for _, script := range detectableScripts {
+			if unicode.Is(script, r) {
+				counts[script]++
+				total++
+				break
+			}
+		}
+	}
+	if total == 0 {
+		return "", false
+	}
+
+	// Japanese mixes Kana with Han and Korean may carry some Hanja,
+	// so any Kana or Hangul settles the question before Han does.
+	switch {
+	case counts[unicode.Hiragana]+counts[unicode.Katakana] > 0:
+		return LangJapanese, true
+	case counts[unicode.Hangul] > 0:
+		return LangKorean, true
+	}
+
+	var dominant *unicode.RangeTable
+	for _, script := range detectableScripts {
+		if dominant == nil || counts[script] > counts[dominant] {
+			dominant = script
+		}
+	}
+
+	switch dominant {
+	case unicode.Han:
+		return detectChinese(text), true
+	case unicode.Arabic:
+		if strings.ContainsAny(text, "پچژگکی") {
+			return LangFarsi, true
+		}
+		return LangArabic, true
+	case unicode.Cyrillic:
+		if strings.ContainsAny(strings.ToLower(text), "іїєґ") {
+			return LangUkrainian, true
+		}
+		return LangRussian, true
+	case unicode.Hebrew:
+		return LangHebrew, true
+	case unicode.Thai:
+		return LangThai, true
+	case unicode.Devanagari:
+		return LangHindi, true
+	case unicode.Bengali:
+		return LangBengali, true
+	default:
+		return detectLatin(text)
+	}
+}
+
+var detectableScripts = []*unicode.RangeTable{
+	unicode.Latin,
+	unicode.Han,
+	unicode.Hiragana,
+	unicode.Katakana,
+	unicode.Hangul,
+	unicode.Arabic,
+	unicode.Cyrillic,
+	unicode.Hebrew,
+	unicode.Thai,
+	unicode.Devanagari,
+	unicode.Bengali,
+}
+
+// Characters that differ between Traditional and Simplified Chinese,
+// at the same positions in both strings.
+const (
+	traditionalHan = "們個這來時說國會對學為開關還進體發電頭義讓實話點應嗎愛貓聽見貝車東長門問間馬鳥魚龍萬與書樂歡慶節氣親"
+	simplifiedHan  = "们个这来时说国会对学为开关还进体发电头义让实话点应吗爱猫听见贝车东长门问间马鸟鱼龙万与书乐欢庆节气亲"
+)
+
+func detectChinese(text string) Language {
+	traditional, simplified := 0, 0
+	for _, r := range text {
+		if strings.ContainsRune(traditionalHan, r) {
+			traditional++
+		} else if strings.ContainsRune(simplifiedHan, r) {
+			simplified++
+		}
+	}
+	if traditional > simplified {
+		return LangChineseTraditional
+	}
+	return LangChineseSimplified
+}
+
+// latinProfiles are common words, including popular GIF searches,
+// of the Latin script languages that GIPHY supports.
+var latinProfiles = map[Language]string{
+	LangEnglish:    "the and you love happy birthday thank thanks good morning night hello yes no cat dog funny dance party sad cry friday weekend excited congratulations sorry with this that what are omg wow",
+	LangSpanish:    "el la los las de que y feliz cumpleaños gracias buenos días noches hola amor te quiero gato perro gracioso baile fiesta triste llorar sí qué por favor muy bien adiós",
+	LangPortuguese: "o os as de que e feliz aniversário obrigado obrigada bom dia boa noite olá oi amor te amo gato cachorro engraçado dança festa triste chorar sim não muito você beijo parabéns",
+	LangFrench:     "le la les de et que joyeux anniversaire merci bonjour bonne nuit salut amour je t'aime chat chien drôle danse fête triste pleurer oui non très bien avec pour c'est",
+	LangGerman:     "der die das und ich du nicht alles gute zum geburtstag danke guten morgen nacht hallo liebe katze hund lustig tanzen party traurig weinen ja nein sehr gut mit ist",
+	LangItalian:    "il la di che e buon compleanno grazie buongiorno buonanotte ciao amore ti amo gatto cane divertente ballare festa triste piangere sì no molto bene con per sono",
+	LangDutch:      "de het een en van ik je gefeliciteerd fijne verjaardag dank bedankt goedemorgen goedenacht hallo liefde kat hond grappig dansen feest verdrietig huilen ja nee heel goed met is",
+	LangIndonesian: "selamat ulang tahun terima kasih pagi malam halo cinta aku kamu kucing anjing lucu menari pesta sedih menangis ya tidak sangat baik dan yang dengan untuk ini itu banget bisa gak",
+	LangMalay:      "selamat hari jadi terima kasih pagi malam helo cinta saya awak kucing anjing kelakar menari parti sedih menangis ya tidak sangat baik dan yang dengan untuk ini itu boleh tak macam",
+	LangTurkish:    "ve bir bu çok mutlu doğum günün iyi teşekkürler günaydın geceler merhaba aşk seni seviyorum kedi köpek komik dans parti üzgün ağlamak evet hayır tamam güzel",
+	LangPolish:     "i w nie to jest się wszystkiego najlepszego urodziny dziękuję dzień dobry dobranoc cześć miłość kocham cię kot pies śmieszne taniec impreza smutny płakać tak bardzo",
+	LangRomanian:   "și de la în nu un o mulți ani mulțumesc bună dimineața noapte salut dragoste te iubesc pisică câine amuzant dans petrecere trist plâng da foarte bine",
+	LangHungarian:  "a az és nem boldog születésnapot köszönöm jó reggelt éjszakát szia szerelem szeretlek macska kutya vicces tánc buli szomorú sírni igen nagyon",
+	LangSwedish:    "och att det är jag du inte grattis på födelsedagen tack god morgon natt hej kärlek älskar dig katt hund rolig dansa fest ledsen gråta ja nej mycket bra med",
+	LangDanish:     "og at det er jeg du ikke tillykke med fødselsdagen tak godmorgen godnat hej kærlighed elsker dig kat hund sjov danse fest trist græde ja nej meget godt",
+	LangNorwegian:  "og at det er jeg du ikke gratulerer med dagen takk god morgen natt hei kjærlighet elsker deg katt hund morsom danse fest trist gråte ja nei veldig bra",
+	LangCzech:      "a je to se na že všechno nejlepší k narozeninám děkuji dobré ráno dobrou noc ahoj láska miluji tě kočka pes vtipné tanec párty smutný plakat ano ne moc dobře",
+	LangFinnish:    "ja on ei se että hyvää syntymäpäivää kiitos huomenta yötä hei rakkaus rakastan sinua kissa koira hauska tanssi juhlat surullinen itkeä kyllä erittäin hyvä",
+	LangFilipino:   "ang ng sa mga at ako ikaw maligayang kaarawan salamat magandang umaga gabi kumusta mahal kita pusa aso nakakatawa sayaw malungkot iyak oo hindi talaga po",
+	LangVietnamese: "chúc mừng sinh nhật cảm ơn chào buổi sáng ngủ ngon yêu em anh mèo chó buồn cười nhảy tiệc khóc có không rất tốt và",
+}
+
+type latinProfile struct {
+	words    map[string]bool
+	trigrams map[string]bool
+}
+
+var latinIndex = func() map[Language]*latinProfile {
+	index := make(map[Language]*latinProfile, len(latinProfiles))
+	for lang, words := range latinProfiles {
+		profile := &latinProfile{words: make(map[string]bool), trigrams: make(map[string]bool)}
+		for _, word := range strings.Fields(words) {
+			profile.words[word] = true
+			for _, trigram := range trigramsOf(word) {
+				profile.trigrams[trigram] = true
+			}
+		}
+		index[lang] = profile
+	}
+	return index
+}()
+
+// trigramsOf returns the character trigrams of word padded with spaces,
+// so that "gato" yields " ga", "gat", "ato" and "to ".
+func trigramsOf(word string) []string {
+	runes := []rune(" " + word + " ")
+	var trigrams []string
+	for i := 0; i+3 <= len(runes); i++ {
+		trigrams = append(trigrams, string(runes[i:i+3]))
+	}
+	return trigrams
+}
+
+const (
+	// A whole word match is worth this many trigram matches.
+	wordMatchWeight = 3
+
+	// A lone trigram match is too weak a signal to settle on a language.
+	minLatinScore = 2
+)
+
+func detectLatin(text string) (Language, bool) {
+	for _, r := range strings.ToLower(text) {
+		// Vietnamese stacks tone marks on vowels, which Unicode
+		// precomposes in the Latin Extended Additional block.
+		if r == 'ơ' || r == 'ư' || r == 'đ' || (r >= 'ạ' && r <= 'ỹ') {
+			return LangVietnamese, true
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) == 0 {
+		return "", false
+	}
+
+	var best Language
+	bestScore, runnerUp := 0, 0
+	// Iterate in registry order so that ties resolve deterministically.
+	for _, info := range languageRegistry {
+		profile, ok := latinIndex[info.Code]
+		if !ok {
+			continue
+		}
+		score := 0
+		for _, word := range words {
+			if profile.words[word] {
+				score += wordMatchWeight
+			}
+			for _, trigram := range trigramsOf(word) {
+				if profile.trigrams[trigram] {
+					score++
+				}
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, runnerUp = info.Code, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if bestScore < minLatinScore || bestScore == runnerUp {
+		return "", false
+	}
+	return best, true
+}
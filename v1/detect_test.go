@@ -0,0 +1,114 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := [...]struct {
+		text   string
+		want   giphy.Language
+		wantOk bool
+	}{
+		0:  {text: "happy birthday", want: giphy.LangEnglish, wantOk: true},
+		1:  {text: "feliz cumpleaños", want: giphy.LangSpanish, wantOk: true},
+		2:  {text: "feliz aniversário", want: giphy.LangPortuguese, wantOk: true},
+		3:  {text: "joyeux anniversaire", want: giphy.LangFrench, wantOk: true},
+		4:  {text: "alles gute zum geburtstag", want: giphy.LangGerman, wantOk: true},
+		5:  {text: "wszystkiego najlepszego", want: giphy.LangPolish, wantOk: true},
+		6:  {text: "grattis på födelsedagen", want: giphy.LangSwedish, wantOk: true},
+		7:  {text: "tillykke med fødselsdagen", want: giphy.LangDanish, wantOk: true},
+		8:  {text: "gratulerer med dagen", want: giphy.LangNorwegian, wantOk: true},
+		9:  {text: "chúc mừng sinh nhật", want: giphy.LangVietnamese, wantOk: true},
+		10: {text: "お誕生日おめでとう", want: giphy.LangJapanese, wantOk: true},
+		11: {text: "생일 축하해", want: giphy.LangKorean, wantOk: true},
+		12: {text: "生日快乐", want: giphy.LangChineseSimplified, wantOk: true},
+		13: {text: "生日快樂", want: giphy.LangChineseTraditional, wantOk: true},
+		14: {text: "с днем рождения", want: giphy.LangRussian, wantOk: true},
+		15: {text: "щасливі їжачки", want: giphy.LangUkrainian, wantOk: true},
+		16: {text: "عيد ميلاد سعيد", want: giphy.LangArabic, wantOk: true},
+		17: {text: "تولدت مبارک", want: giphy.LangFarsi, wantOk: true},
+		18: {text: "יום הולדת שמח", want: giphy.LangHebrew, wantOk: true},
+		19: {text: "สุขสันต์วันเกิด", want: giphy.LangThai, wantOk: true},
+		20: {text: "जन्मदिन मुबारक", want: giphy.LangHindi, wantOk: true},
+		21: {text: "শুভ জন্মদিন", want: giphy.LangBengali, wantOk: true},
+
+		// Too little evidence to decide.
+		22: {text: ""},
+		23: {text: "2017 !!!"},
+		24: {text: "lol"},
+		25: {text: "ok"},
+	}
+
+	for i, tt := range tests {
+		got, ok := giphy.DetectLanguage(tt.text)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("#%d: %q: got=(%q, %v) want=(%q, %v)", i, tt.text, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+type langRecorder struct {
+	mu    sync.Mutex
+	langs []string
+}
+
+func (lr *langRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	lr.mu.Lock()
+	lr.langs = append(lr.langs, req.URL.Query().Get("lang"))
+	lr.mu.Unlock()
+	return blankDataResp(), nil
+}
+
+func TestSearchDetectsLanguage(t *testing.T) {
+	tests := [...]struct {
+		req  *giphy.Request
+		want string
+	}{
+		0: {req: &giphy.Request{Query: "feliz cumpleaños", DetectLanguage: true}, want: "es"},
+		1: {req: &giphy.Request{Query: "с днем рождения", DetectLanguage: true}, want: "ru"},
+		// An explicit Language always wins.
+		2: {req: &giphy.Request{Query: "feliz cumpleaños", Language: giphy.LangPortuguese, DetectLanguage: true}, want: "pt"},
+		3: {req: &giphy.Request{Query: "feliz cumpleaños"}, want: ""},
+		4: {req: &giphy.Request{Query: "lol", DetectLanguage: true}, want: ""},
+	}
+
+	for i, tt := range tests {
+		client, err := giphy.NewClient(testAPIKey1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorder := new(langRecorder)
+		client.SetHTTPRoundTripper(recorder)
+
+		res, err := client.Search(context.Background(), tt.req)
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		for range res.Pages {
+		}
+		if len(recorder.langs) != 1 || recorder.langs[0] != tt.want {
+			t.Errorf("#%d: got langs=%q want=%q", i, recorder.langs, tt.want)
+		}
+	}
+}
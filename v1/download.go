@@ -0,0 +1,242 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"go.opencensus.io/trace"
+
+	"github.com/orijtech/otils"
+)
+
+// MediaFormat is the encoding of a rendition to download.
+type MediaFormat string
+
+const (
+	MediaGIF  MediaFormat = "gif"
+	MediaMP4  MediaFormat = "mp4"
+	MediaWebP MediaFormat = "webp"
+)
+
+func (mf MediaFormat) contentType() string {
+	switch mf {
+	case MediaMP4:
+		return "video/mp4"
+	case MediaWebP:
+		return "image/webp"
+	default:
+		return "image/gif"
+	}
+}
+
+type DownloadRequest struct {
+	Giph *Giph `json:"giph"`
+
+	// Rendition defaults to RenditionOriginal.
+	Rendition Rendition `json:"rendition"`
+	// Format defaults to MediaGIF.
+	Format MediaFormat `json:"format"`
+
+	// OnProgress, if set, is invoked as the media is received
+	// with the number of bytes so far and the expected total,
+	// which is -1 if GIPHY did not report the size.
+	OnProgress func(received, total int64) `json:"-"`
+}
+
+var (
+	errNilDownloadRequest = errors.New("expecting a non-nil DownloadRequest with a Giph")
+	errUnknownMediaFormat = errors.New("expecting MediaGIF, MediaMP4 or MediaWebP")
+)
+
+// SizeMismatchError is returned when the number of bytes downloaded
+// differs from the size that GIPHY reported for the rendition.
+type SizeMismatchError struct {
+	URL  string `json:"url"`
+	Want int64  `json:"want"`
+	Got  int64  `json:"got"`
+}
+
+func (sme *SizeMismatchError) Error() string {
+	return fmt.Sprintf("%s: got %d bytes, expected %d", sme.URL, sme.Got, sme.Want)
+}
+
+// ContentTypeError is returned when the server sent
+// media other than the requested MediaFormat.
+type ContentTypeError struct {
+	URL  string      `json:"url"`
+	Want MediaFormat `json:"want"`
+	Got  string      `json:"got"`
+}
+
+func (cte *ContentTypeError) Error() string {
+	return fmt.Sprintf("%s: got content type %q, expected %q", cte.URL, cte.Got, cte.Want.contentType())
+}
+
+// media resolves the URL and the reported size of the requested media.
+func (dreq *DownloadRequest) media() (theURL string, size int64, err error) {
+	if dreq == nil || dreq.Giph == nil {
+		return "", 0, errNilDownloadRequest
+	}
+	rendition := dreq.Rendition
+	if rendition == "" {
+		rendition = RenditionOriginal
+	}
+	gif := dreq.Giph.Rendition(rendition)
+	if gif == nil {
+		return "", 0, fmt.Errorf("giph %q has no %q rendition", dreq.Giph.ID, rendition)
+	}
+
+	switch dreq.Format {
+	case MediaGIF, "":
+		theURL, size = gif.URL, gif.Size
+	case MediaMP4:
+		theURL, size = gif.MP4, gif.MP4Size
+	case MediaWebP:
+		theURL, size = gif.Webp, gif.WebpSize
+	default:
+		return "", 0, errUnknownMediaFormat
+	}
+	if theURL == "" {
+		return "", 0, fmt.Errorf("giph %q has no %s for the %q rendition", dreq.Giph.ID, dreq.format(), rendition)
+	}
+	return theURL, size, nil
+}
+
+func (dreq *DownloadRequest) format() MediaFormat {
+	if dreq.Format == "" {
+		return MediaGIF
+	}
+	return dreq.Format
+}
+
+// Download streams the requested rendition of a Giph into w, through the
+// Client's transport, returning the number of bytes written. The download
+// fails if its size or content type differ from what GIPHY advertised.
+func (c *Client) Download(ctx context.Context, dreq *DownloadRequest, w io.Writer) (int64, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Download")
+	defer span.End()
+
+	theURL, size, err := dreq.media()
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.openMedia(ctx, theURL, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	body := bufio.NewReader(res.Body)
+	if err := checkContentType(theURL, res.Header.Get("Content-Type"), body, dreq.format()); err != nil {
+		return 0, err
+	}
+
+	total := size
+	if total <= 0 {
+		total = -1
+	}
+	var src io.Reader = body
+	if dreq.OnProgress != nil {
+		src = &progressReader{r: body, total: total, onProgress: dreq.OnProgress}
+	}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return n, ctxErr
+		}
+		return n, err
+	}
+	if size > 0 && n != size {
+		return n, &SizeMismatchError{URL: theURL, Want: size, Got: n}
+	}
+	return n, nil
+}
+
+// openMedia requests the media at theURL, starting from byte offset.
+// Responses with a non-2XX status are closed and returned with an error.
+func (c *Client) openMedia(ctx context.Context, theURL string, offset int64) (*http.Response, error) {
+	httpReq, err := http.NewRequest("GET", theURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		httpReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := c.httpClient().Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if !otils.StatusOK(res.StatusCode) {
+		res.Body.Close()
+		return res, errors.New(res.Status)
+	}
+	return res, nil
+}
+
+// checkContentType compares the declared content type with format. Servers
+// that declare no specific type have the start of the body sniffed instead.
+func checkContentType(theURL, declared string, body *bufio.Reader, format MediaFormat) error {
+	mediaType, _, _ := mime.ParseMediaType(declared)
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		// Peek only fails here when the body is
+		// shorter than 512 bytes, which is fine.
+		head, _ := body.Peek(512)
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	}
+	if mediaType != format.contentType() {
+		return &ContentTypeError{URL: theURL, Want: format, Got: mediaType}
+	}
+	return nil
+}
+
+// DownloadToFile is like Download but saves the media at path. The media
+// is written to a temporary file next to path and only renamed over path
+// once the download succeeds, so a failure leaves any existing file intact.
+func (c *Client) DownloadToFile(ctx context.Context, dreq *DownloadRequest, path string) (int64, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).DownloadToFile")
+	defer span.End()
+
+	if _, _, err := dreq.media(); err != nil {
+		return 0, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	n, err := c.Download(ctx, dreq, f)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return n, err
+	}
+	return n, nil
+}
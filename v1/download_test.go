@@ -0,0 +1,228 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+var (
+	gifBody = append([]byte("GIF89a"), bytes.Repeat([]byte{0x2c}, 2042)...)
+	mp4Body = append([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), bytes.Repeat([]byte{0}, 1000)...)
+)
+
+func mediaServer() *httptest.Server {
+	mux := http.NewServeMux()
+	serve := func(route, contentType string, body []byte) {
+		mux.HandleFunc(route, func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Type", contentType)
+			rw.Write(body)
+		})
+	}
+	serve("/ok.gif", "image/gif", gifBody)
+	serve("/ok.mp4", "application/octet-stream", mp4Body)
+	serve("/short.gif", "image/gif", gifBody[:1024])
+	serve("/page.gif", "text/html; charset=utf-8", gifBody)
+	mux.HandleFunc("/stalled.gif", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "image/gif")
+		rw.Write(gifBody[:1024])
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	})
+	return httptest.NewServer(mux)
+}
+
+func mediaGiph(baseURL, name string) *giphy.Giph {
+	return &giphy.Giph{
+		ID: "media",
+		Sizes: map[string]*giphy.GIF{
+			"original": {
+				URL:     baseURL + "/" + name + ".gif",
+				Size:    int64(len(gifBody)),
+				MP4:     baseURL + "/ok.mp4",
+				MP4Size: int64(len(mp4Body)),
+			},
+			"fixed_height": {URL: baseURL + "/ok.gif"},
+		},
+	}
+}
+
+func TestDownload(t *testing.T) {
+	server := mediaServer()
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := [...]struct {
+		dreq     *giphy.DownloadRequest
+		want     []byte
+		checkErr func(error) bool
+	}{
+		0: {dreq: nil, checkErr: func(err error) bool { return err != nil }},
+		1: {dreq: &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "ok")}, want: gifBody},
+		2: {
+			// The advertised size is unknown so it is not checked.
+			dreq: &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "ok"), Rendition: giphy.RenditionFixedHeight},
+			want: gifBody,
+		},
+		3: {
+			// The generic content type is resolved by sniffing.
+			dreq: &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "ok"), Format: giphy.MediaMP4},
+			want: mp4Body,
+		},
+		4: {
+			dreq:     &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "ok"), Format: giphy.MediaWebP},
+			checkErr: func(err error) bool { return err != nil },
+		},
+		5: {
+			dreq:     &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "ok"), Rendition: giphy.Rendition4K},
+			checkErr: func(err error) bool { return err != nil },
+		},
+		6: {
+			dreq: &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "short")},
+			checkErr: func(err error) bool {
+				var sme *giphy.SizeMismatchError
+				return errors.As(err, &sme) && sme.Got == 1024 && sme.Want == int64(len(gifBody))
+			},
+		},
+		7: {
+			dreq: &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "page")},
+			checkErr: func(err error) bool {
+				var cte *giphy.ContentTypeError
+				return errors.As(err, &cte) && cte.Got == "text/html"
+			},
+		},
+		8: {
+			dreq:     &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "missing")},
+			checkErr: func(err error) bool { return err != nil },
+		},
+	}
+
+	for i, tt := range tests {
+		var lastReceived, lastTotal int64
+		if tt.dreq != nil {
+			tt.dreq.OnProgress = func(received, total int64) {
+				lastReceived, lastTotal = received, total
+			}
+		}
+		buf := new(bytes.Buffer)
+		n, err := client.Download(context.Background(), tt.dreq, buf)
+		if tt.checkErr != nil {
+			if !tt.checkErr(err) {
+				t.Errorf("#%d: unexpected err: %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if n != int64(len(tt.want)) || !bytes.Equal(buf.Bytes(), tt.want) {
+			t.Errorf("#%d: got %d bytes want %d", i, n, len(tt.want))
+		}
+		if lastReceived != n {
+			t.Errorf("#%d: last progress %d/%d want %d", i, lastReceived, lastTotal, n)
+		}
+	}
+}
+
+func TestDownloadCancel(t *testing.T) {
+	server := mediaServer()
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dreq := &giphy.DownloadRequest{
+		Giph: mediaGiph(server.URL, "stalled"),
+		OnProgress: func(received, total int64) {
+			if received >= 1024 {
+				cancel()
+			}
+		},
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Download(ctx, dreq, new(bytes.Buffer))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("gotErr: %v want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Download did not return after cancellation")
+	}
+}
+
+func TestDownloadToFile(t *testing.T) {
+	server := mediaServer()
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	okPath := filepath.Join(dir, "ok.gif")
+	if _, err := client.DownloadToFile(context.Background(), &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "ok")}, okPath); err != nil {
+		t.Fatalf("ok: %v", err)
+	}
+	if got, err := os.ReadFile(okPath); err != nil || !bytes.Equal(got, gifBody) {
+		t.Errorf("ok: got %d bytes err=%v want %d bytes", len(got), err, len(gifBody))
+	}
+
+	shortPath := filepath.Join(dir, "short.gif")
+	if _, err := client.DownloadToFile(context.Background(), &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "short")}, shortPath); err == nil {
+		t.Error("short: expected a non-nil error")
+	}
+	if _, err := os.Stat(shortPath); !os.IsNotExist(err) {
+		t.Errorf("short: expected the partial file to be removed, got err=%v", err)
+	}
+
+	// A failed download must not touch an existing file.
+	existing := []byte("keep me")
+	if err := os.WriteFile(shortPath, existing, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DownloadToFile(context.Background(), &giphy.DownloadRequest{Giph: mediaGiph(server.URL, "short")}, shortPath); err == nil {
+		t.Error("short: expected a non-nil error")
+	}
+	if got, err := os.ReadFile(shortPath); err != nil || !bytes.Equal(got, existing) {
+		t.Errorf("existing: got %q err=%v want %q", got, err, existing)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 2 {
+		t.Errorf("expected only ok.gif and short.gif in the directory, got %v err=%v", entries, err)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"time"
+)
+
+// Filters drop results client-side, for criteria that GIPHY cannot
+// search by. Zero values leave the corresponding criteria unchecked.
+type Filters struct {
+	// Rendition is the one whose dimensions, byte size and formats
+	// are checked, defaulting to RenditionOriginal. Giphs without
+	// it are dropped if any of those criteria are set.
+	Rendition Rendition `json:"rendition,omitempty"`
+
+	MinWidth  int `json:"min_width,omitempty"`
+	MaxWidth  int `json:"max_width,omitempty"`
+	MinHeight int `json:"min_height,omitempty"`
+	MaxHeight int `json:"max_height,omitempty"`
+
+	// MaxBytes applies to the GIF of the rendition.
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+
+	// MinAspect and MaxAspect bound width divided by height.
+	MinAspect float64 `json:"min_aspect,omitempty"`
+	MaxAspect float64 `json:"max_aspect,omitempty"`
+
+	// RequireFormats lists formats that the rendition must be available in.
+	RequireFormats []MediaFormat `json:"require_formats,omitempty"`
+
+	// MaxRating drops Giphs with a higher, unknown or missing rating.
+	MaxRating Rating `json:"max_rating,omitempty"`
+
+	// RequireUser drops Giphs without a known uploader.
+	RequireUser bool `json:"require_user,omitempty"`
+
+	// MinFrames drops Giphs with fewer, or an unknown number of, frames.
+	MinFrames uint `json:"min_frames,omitempty"`
+}
+
+func (f *Filters) rendition() Rendition {
+	if f.Rendition == "" {
+		return RenditionOriginal
+	}
+	return f.Rendition
+}
+
+func (f *Filters) checksRendition() bool {
+	return f.MinWidth > 0 || f.MaxWidth > 0 || f.MinHeight > 0 || f.MaxHeight > 0 ||
+		f.MaxBytes > 0 || f.MinAspect > 0 || f.MaxAspect > 0 || len(f.RequireFormats) > 0
+}
+
+// Allows reports whether giph passes every criteria of f.
+func (f *Filters) Allows(giph *Giph) bool {
+	if f == nil {
+		return true
+	}
+	if giph == nil {
+		return false
+	}
+
+	if f.MaxRating != "" && !withinRating(giph.Rating, f.MaxRating) {
+		return false
+	}
+	if f.RequireUser && giph.User == nil && giph.Owner == "" {
+		return false
+	}
+	if f.MinFrames > 0 {
+		frames := giph.FrameCount
+		if original := giph.Rendition(RenditionOriginal); original != nil && original.Frames > 0 {
+			frames = original.Frames
+		}
+		if frames < f.MinFrames {
+			return false
+		}
+	}
+
+	if !f.checksRendition() {
+		return true
+	}
+	gif := giph.Rendition(f.rendition())
+	if gif == nil {
+		return false
+	}
+	switch {
+	case f.MinWidth > 0 && gif.Width < f.MinWidth,
+		f.MaxWidth > 0 && gif.Width > f.MaxWidth,
+		f.MinHeight > 0 && gif.Height < f.MinHeight,
+		f.MaxHeight > 0 && gif.Height > f.MaxHeight,
+		f.MaxBytes > 0 && (gif.Size <= 0 || gif.Size > f.MaxBytes):
+		return false
+	}
+	if f.MinAspect > 0 || f.MaxAspect > 0 {
+		if gif.Width <= 0 || gif.Height <= 0 {
+			return false
+		}
+		aspect := float64(gif.Width) / float64(gif.Height)
+		if (f.MinAspect > 0 && aspect < f.MinAspect) || (f.MaxAspect > 0 && aspect > f.MaxAspect) {
+			return false
+		}
+	}
+	if len(f.RequireFormats) > 0 {
+		available := make(map[MediaFormat]bool)
+		for _, sel := range gif.selections(f.rendition()) {
+			available[sel.Format] = true
+		}
+		for _, format := range f.RequireFormats {
+			if !available[format] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (f *Filters) validate(addErr func(field, format string, args ...interface{})) {
+	if f.Rendition != "" && !f.Rendition.IsKnown() {
+		addErr("Filters.Rendition", "unknown rendition %q", f.Rendition)
+	}
+	if f.MaxWidth > 0 && f.MinWidth > f.MaxWidth {
+		addErr("Filters.MinWidth", "%d exceeds MaxWidth %d", f.MinWidth, f.MaxWidth)
+	}
+	if f.MaxHeight > 0 && f.MinHeight > f.MaxHeight {
+		addErr("Filters.MinHeight", "%d exceeds MaxHeight %d", f.MinHeight, f.MaxHeight)
+	}
+	if f.MaxAspect > 0 && f.MinAspect > f.MaxAspect {
+		addErr("Filters.MinAspect", "%g exceeds MaxAspect %g", f.MinAspect, f.MaxAspect)
+	}
+	for _, format := range f.RequireFormats {
+		if format != MediaGIF && format != MediaMP4 && format != MediaWebP {
+			addErr("Filters.RequireFormats", "unknown format %q", format)
+		}
+	}
+	if f.MaxRating != "" && !f.MaxRating.isKnown() {
+		addErr("Filters.MaxRating", "unknown rating %q", f.MaxRating)
+	}
+}
+
+// pageFiller assembles Pages out of as many GIPHY pages as it takes
+// to fill them up to the page limit with Giphs that pass the filters.
+type pageFiller struct {
+	client     *Client
+	req        *Request
+	endpoint   Endpoint
+	safety     *safetyPolicy
+	seen       SeenSet
+	cancelChan <-chan bool
+
+	// leftover holds the Giphs that did not fit into the last Page.
+	leftover   []*Giph
+	exhausted  bool
+	totalCount uint64
+}
+
+func (pf *pageFiller) filter(giphs []*Giph, stats *FilterStats) []*Giph {
+	giphs = pf.safety.filter(giphs, stats)
+	kept := giphs[:0]
+	for _, giph := range giphs {
+		if !pf.req.Filters.Allows(giph) {
+			stats.Filters++
+			continue
+		}
+		kept = append(kept, giph)
+	}
+	return kept
+}
+
+// deliver moves leftover Giphs into page until it holds limit of them.
+// Deduplication happens here, so that only the Giphs actually
+// delivered are recorded as seen and the leftover never are.
+func (pf *pageFiller) deliver(page *Page, limit int) {
+	for len(pf.leftover) > 0 && len(page.Giphs) < limit {
+		giph := pf.leftover[0]
+		pf.leftover = pf.leftover[1:]
+		if pf.seen != nil && pf.req.Dedupe.isDuplicate(pf.seen, giph) {
+			page.Filtered.Duplicates++
+			continue
+		}
+		page.Giphs = append(page.Giphs, giph)
+	}
+}
+
+// fill populates page starting from offset, returning the
+// GIPHY results consumed and whether there might be more.
+func (pf *pageFiller) fill(ctx context.Context, page *Page, offset uint64) (*Pagination, bool) {
+	limit := int(pf.req.LimitPerPage)
+	if limit == 0 {
+		// GIPHY's default page size.
+		limit = 25
+	}
+
+	page.Filtered = new(FilterStats)
+	consumed := uint64(0)
+
+	for {
+		pf.deliver(page, limit)
+		if pf.exhausted || len(page.Giphs) >= limit {
+			break
+		}
+		if consumed > 0 {
+			select {
+			case <-pf.cancelChan:
+				pf.exhausted = true
+				continue
+			case <-time.After(pf.req.throttleDuration()):
+			}
+		}
+		if offset+consumed > MaxOffset {
+			pf.exhausted = true
+			break
+		}
+
+		res := new(Response)
+		warnings, err := pf.client.getPage(ctx, string(pf.endpoint), pf.req.pager(offset+consumed), res)
+		page.Warnings = append(page.Warnings, warnings...)
+		if err != nil {
+			page.Err = err
+			return nil, false
+		}
+		if len(res.Giphs) == 0 {
+			pf.exhausted = true
+			break
+		}
+
+		count := uint64(len(res.Giphs))
+		if res.Pagination != nil {
+			pf.totalCount = res.Pagination.TotalCount
+			if res.Pagination.Count > 0 {
+				count = res.Pagination.Count
+			}
+		}
+		consumed += count
+		for _, giph := range res.Giphs {
+			giph.Meta = res.Meta
+		}
+		page.Meta = res.Meta
+		pf.leftover = append(pf.leftover, pf.filter(res.Giphs, page.Filtered)...)
+	}
+
+	page.Pagination = &Pagination{TotalCount: pf.totalCount, Offset: offset, Count: consumed}
+	return page.Pagination, !pf.exhausted || len(pf.leftover) > 0
+}
@@ -0,0 +1,171 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestFiltersAllows(t *testing.T) {
+	giph := &giphy.Giph{
+		ID:     "filtered",
+		Rating: "pg",
+		User:   &giphy.User{Username: "orijtech"},
+		Sizes: map[string]*giphy.GIF{
+			"original": {
+				URL: "https://media.giphy.com/media/filtered/giphy.gif", Width: 480, Height: 270,
+				Size: 2000000, Frames: 40, MP4: "https://media.giphy.com/media/filtered/giphy.mp4",
+			},
+			"fixed_width": {URL: "https://media.giphy.com/media/filtered/200w.gif", Width: 200, Height: 113},
+		},
+	}
+
+	tests := [...]struct {
+		filters *giphy.Filters
+		want    bool
+	}{
+		0:  {filters: nil, want: true},
+		1:  {filters: &giphy.Filters{}, want: true},
+		2:  {filters: &giphy.Filters{MinWidth: 480, MaxHeight: 270}, want: true},
+		3:  {filters: &giphy.Filters{MinWidth: 481}, want: false},
+		4:  {filters: &giphy.Filters{MaxBytes: 1000000}, want: false},
+		5:  {filters: &giphy.Filters{MinAspect: 1.7, MaxAspect: 1.8}, want: true},
+		6:  {filters: &giphy.Filters{MaxAspect: 1.5}, want: false},
+		7:  {filters: &giphy.Filters{RequireFormats: []giphy.MediaFormat{giphy.MediaGIF, giphy.MediaMP4}}, want: true},
+		8:  {filters: &giphy.Filters{RequireFormats: []giphy.MediaFormat{giphy.MediaWebP}}, want: false},
+		9:  {filters: &giphy.Filters{Rendition: giphy.RenditionFixedWidth, MaxWidth: 200}, want: true},
+		10: {filters: &giphy.Filters{Rendition: giphy.RenditionFixedWidth, RequireFormats: []giphy.MediaFormat{giphy.MediaMP4}}, want: false},
+		11: {filters: &giphy.Filters{Rendition: giphy.RenditionHD, MinWidth: 1}, want: false},
+		12: {filters: &giphy.Filters{MaxRating: giphy.RatingPG}, want: true},
+		13: {filters: &giphy.Filters{MaxRating: giphy.RatingGeneral}, want: false},
+		14: {filters: &giphy.Filters{RequireUser: true, MinFrames: 40}, want: true},
+		15: {filters: &giphy.Filters{MinFrames: 41}, want: false},
+	}
+
+	for i, tt := range tests {
+		if got := tt.filters.Allows(giph); got != tt.want {
+			t.Errorf("#%d: got=%v want=%v", i, got, tt.want)
+		}
+	}
+
+	anonymous := &giphy.Giph{ID: "anonymous"}
+	if (&giphy.Filters{RequireUser: true}).Allows(anonymous) {
+		t.Error("expected a Giph without a user to be dropped")
+	}
+}
+
+func TestFiltersFillPages(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchRoute})
+
+	res, err := client.Search(context.Background(), &giphy.Request{
+		Query:              "hip hop",
+		ThrottleDurationMs: giphy.NoThrottle,
+		Filters:            &giphy.Filters{MaxRating: giphy.RatingGeneral},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fixtures hold 15, 12, 22 and 23 "g" rated Giphs.
+	wantPages := [...]struct {
+		giphs, filtered int
+		pagination      giphy.Pagination
+	}{
+		0: {giphs: 25, filtered: 23, pagination: giphy.Pagination{TotalCount: 22162, Offset: 0, Count: 50}},
+		1: {giphs: 25, filtered: 5, pagination: giphy.Pagination{TotalCount: 22162, Offset: 50, Count: 50}},
+		2: {giphs: 22, filtered: 0, pagination: giphy.Pagination{TotalCount: 22162, Offset: 100, Count: 0}},
+	}
+	var pages []*giphy.Page
+	for page := range res.Pages {
+		pages = append(pages, page)
+	}
+	if len(pages) != len(wantPages) {
+		t.Fatalf("got %d pages want %d", len(pages), len(wantPages))
+	}
+	for i, page := range pages {
+		want := wantPages[i]
+		if page.Err != nil {
+			t.Errorf("#%d: err: %v", i, page.Err)
+			continue
+		}
+		if len(page.Giphs) != want.giphs {
+			t.Errorf("#%d: got %d giphs want %d", i, len(page.Giphs), want.giphs)
+		}
+		if page.Filtered.Filters != want.filtered || page.Filtered.Total() != want.filtered {
+			t.Errorf("#%d: gotFiltered: %+v want %d", i, page.Filtered, want.filtered)
+		}
+		if *page.Pagination != want.pagination {
+			t.Errorf("#%d: gotPagination: %+v wantPagination: %+v", i, page.Pagination, want.pagination)
+		}
+		for _, giph := range page.Giphs {
+			if giph.Rating != "g" {
+				t.Errorf("#%d: %q is rated %q", i, giph.ID, giph.Rating)
+			}
+		}
+	}
+}
+
+func TestFiltersValidate(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&failingTransport{t: t})
+
+	_, err = client.Trending(context.Background(), &giphy.Request{
+		Filters: &giphy.Filters{MinWidth: 500, MaxWidth: 100, MinAspect: 2, MaxAspect: 1, MaxRating: "nc-17"},
+	})
+	verr, ok := err.(*giphy.ValidationError)
+	if !ok {
+		t.Fatalf("gotErr: %v want a *ValidationError", err)
+	}
+	if len(verr.Errors) != 3 {
+		t.Errorf("got %d field errors want 3: %v", len(verr.Errors), verr)
+	}
+}
+
+func TestFiltersEmptyLastPage(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&firstPageTransport{ids: []string{"a", "b", "c"}})
+
+	res, err := client.Trending(context.Background(), &giphy.Request{
+		ThrottleDurationMs: giphy.NoThrottle,
+		Filters:            &giphy.Filters{RequireUser: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages []*giphy.Page
+	for page := range res.Pages {
+		pages = append(pages, page)
+	}
+	// None of the Giphs has a user, but their drops are still counted.
+	if len(pages) != 1 {
+		t.Fatalf("got %d pages want 1", len(pages))
+	}
+	if page := pages[0]; len(page.Giphs) != 0 || page.Filtered == nil || page.Filtered.Filters != 3 {
+		t.Errorf("got %d giphs, filtered: %+v want 0 giphs, 3 filtered", len(page.Giphs), page.Filtered)
+	}
+}
@@ -16,7 +16,6 @@ package giphy
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -28,6 +27,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
@@ -53,6 +54,22 @@ type Request struct {
 	SortBy        SortOrder `json:"sort_by"`
 	Tag           string    `json:"tag"`
 
+	// DetectLanguage, if set and Language is blank,
+	// sends the language that DetectLanguage infers from Query.
+	DetectLanguage bool `json:"detect_language"`
+
+	// Filters, if set, are applied to the results client-side. Each
+	// Page is then made up of as many GIPHY pages as it takes to hold
+	// LimitPerPage results, unless the results run out.
+	Filters *Filters `json:"filters,omitempty"`
+
+	// Dedupe, if set, drops results that were already delivered.
+	Dedupe *Dedupe `json:"dedupe,omitempty"`
+
+	// RandomID personalizes results for an end user.
+	// See Client.NewRandomID and Session.
+	RandomID string `json:"random_id"`
+
 	ThrottleDurationMs int64 `json:"throttle_duration_ms"`
 }
 
@@ -79,11 +96,19 @@ type GIF struct {
 	URL      string `json:"url"`
 	Width    int    `json:"width,string,omitempty"`
 	Height   int    `json:"height,string,omitempty"`
+	Frames   uint   `json:"frames,string,omitempty"`
 	Size     int64  `json:"size,string,omitempty"`
 	MP4      string `json:"mp4,omitempty"`
 	MP4Size  int64  `json:"mp4_size,string,omitempty"`
 	Webp     string `json:"webp,omitempty"`
 	WebpSize int64  `json:"webp_size,string,omitempty"`
+
+	// Hash is a digest of the media's content, only
+	// set by GIPHY for some renditions e.g. the original.
+	Hash string `json:"hash,omitempty"`
+
+	// Animation is only set once Client.Animation inspects the rendition.
+	Animation *AnimationInfo `json:"-"`
 }
 
 type Giph struct {
@@ -95,21 +120,42 @@ type Giph struct {
 	BitlyGIFURL string `json:"bitly_gif_url,omitempty"`
 	EmbedURL    string `json:"embed_url,omitempty"`
 	Owner       string `json:"username,omitempty"`
+	User        *User  `json:"user,omitempty"`
 	Source      string `json:"source,omitempty"`
 	Rating      string `json:"rating,omitempty"`
 	Caption     string `json:"caption,omitempty"`
 	ContentURL  string `json:"content_url,omitempty"`
+	Title       string `json:"title,omitempty"`
+	RawAltText  string `json:"alt_text,omitempty"`
+
+	IsSticker   *Flag `json:"is_sticker,omitempty"`
+	IsIndexable *Flag `json:"is_indexable,omitempty"`
 
 	SourceTopLevelDomain string `json:"source_tld,omitempty"`
 	SourcePostURL        string `json:"source_post_url,omitempty"`
 
 	ImportDate   *GiphyTime `json:"import_datetime,omitempty"`
 	TrendingDate *GiphyTime `json:"trending_datetime,omitempty"`
+	CreateDate   *GiphyTime `json:"create_datetime,omitempty"`
+	UpdateDate   *GiphyTime `json:"update_datetime,omitempty"`
 
 	Sizes map[string]*GIF `json:"images"`
+	// Images holds the same renditions as Sizes, by name.
+	Images *Images `json:"-"`
+
+	Analytics *Analytics `json:"analytics,omitempty"`
+
+	// Meta is that of the response that the Giph was retrieved in.
+	// It is how calls returning a single Giph expose their response,
+	// which carries no Pagination.
+	Meta *Meta `json:"meta,omitempty"`
+
+	// Video is only set for GIPHY Clips.
+	Video *Video `json:"video,omitempty"`
 
 	ImageOriginalURL string `json:"image_original_url,omitempty"`
 	ImageURL         string `json:"image_url,omitempty"`
+	ImageMP4URL      string `json:"image_mp4_url,omitempty"`
 	FrameCount       uint   `json:"image_frames,string,omitempty"`
 	ImageWidth       int    `json:"image_width,string,omitempty"`
 	ImageHeight      int    `json:"image_height,string,omitempty"`
@@ -159,6 +205,7 @@ func (gt *GiphyTime) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	if blankTimeStr(unquoted) {
+		*gt = GiphyTime{}
 		return nil
 	}
 	t, err := time.Parse(giphyTimeFormat, unquoted)
@@ -169,23 +216,125 @@ func (gt *GiphyTime) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON writes gt back in GIPHY's format, with the
+// zero time as "0000-00-00 00:00:00" just like the API does.
+func (gt GiphyTime) MarshalJSON() ([]byte, error) {
+	if gt.IsZero() {
+		return []byte(strconv.Quote(blankGiphyTimeStr)), nil
+	}
+	return []byte(strconv.Quote(gt.Time().UTC().Format(giphyTimeFormat))), nil
+}
+
+func NewGiphyTime(t time.Time) *GiphyTime {
+	gt := GiphyTime(t)
+	return &gt
+}
+
+func (gt GiphyTime) Time() time.Time {
+	return time.Time(gt)
+}
+
+func (gt GiphyTime) IsZero() bool {
+	return gt.Time().IsZero()
+}
+
+func (gt GiphyTime) String() string {
+	if gt.IsZero() {
+		return blankGiphyTimeStr
+	}
+	return gt.Time().UTC().Format(giphyTimeFormat)
+}
+
+// Flag is a boolean that GIPHY sends back as either 0 or 1.
+type Flag bool
+
+func (f *Flag) UnmarshalJSON(b []byte) error {
+	switch str := string(b); str {
+	case "1", "true", `"1"`, `"true"`:
+		*f = true
+	case "0", "false", `"0"`, `"false"`, `""`:
+		*f = false
+	default:
+		return fmt.Errorf("%s cannot be parsed as a Flag", str)
+	}
+	return nil
+}
+
+func (f Flag) MarshalJSON() ([]byte, error) {
+	if f {
+		return []byte("1"), nil
+	}
+	return []byte("0"), nil
+}
+
+func (f *Flag) IsSet() bool {
+	return f != nil && bool(*f)
+}
+
+// AltText describes the Giph for accessibility, falling back to
+// its title then to its humanized slug if GIPHY sent no alt text.
+func (g *Giph) AltText() string {
+	if g == nil {
+		return ""
+	}
+	if alt := strings.TrimSpace(g.RawAltText); alt != "" {
+		return alt
+	}
+	if title := strings.TrimSpace(g.Title); title != "" {
+		return title
+	}
+	return humanizeSlug(g.Slug, g.ID)
+}
+
+// humanizeSlug turns a slug like "harlem-globetrotters-3o7btPg4hsI2t52hUc"
+// into "Harlem globetrotters", dropping the trailing ID.
+func humanizeSlug(slug, id string) string {
+	slug = strings.TrimSpace(slug)
+	if id != "" {
+		slug = strings.TrimSuffix(strings.TrimSuffix(slug, id), "-")
+	}
+	words := strings.FieldsFunc(slug, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	humanized := strings.Join(words, " ")
+	first, size := utf8.DecodeRuneInString(humanized)
+	return string(unicode.ToUpper(first)) + humanized[size:]
+}
+
 type Pagination struct {
 	TotalCount uint64 `json:"total_count,omitempty"`
 	Offset     uint64 `json:"offset,omitempty"`
 	Count      uint64 `json:"count,omitempty"`
 }
 
+// Meta describes the response that results were delivered in.
+// GIPHY support asks for the ResponseID when investigating issues.
+type Meta struct {
+	Status     int    `json:"status,omitempty"`
+	Msg        string `json:"msg,omitempty"`
+	ResponseID string `json:"response_id,omitempty"`
+}
+
 type Response struct {
-	Giphs      []*Giph                `json:"data,omitempty"`
-	Pagination *Pagination            `json:"pagination,omitempty"`
-	Meta       map[string]interface{} `json:"meta,omitempty"`
+	Giphs      []*Giph     `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Meta       *Meta       `json:"meta,omitempty"`
 }
 
 type Client struct {
 	sync.RWMutex
 
-	rt     http.RoundTripper
-	apiKey string
+	rt        http.RoundTripper
+	apiKey    string
+	uploadURL string
+
+	strictDecoding bool
+	onDecodeReport func(*DecodeReport)
+
+	safety *safetyPolicy
 }
 
 func (c *Client) httpClient() *http.Client {
@@ -226,6 +375,17 @@ type Page struct {
 	Giphs []*Giph `json:"giphs"`
 	Err   error   `json:"error"`
 
+	// Warnings are only set in strict decoding mode.
+	Warnings []*DecodeWarning `json:"warnings,omitempty"`
+
+	Meta       *Meta       `json:"meta,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+
+	// Filtered counts the Giphs that the Client's SafetyPolicy,
+	// Request.Filters and Request.Dedupe dropped, if any is set.
+	// The last Page may hold no Giphs, only the drops of its results.
+	Filtered *FilterStats `json:"filtered,omitempty"`
+
 	PageNumber uint64 `json:"page_number"`
 }
 
@@ -255,6 +415,8 @@ type pager struct {
 	Language Language  `json:"lang"`
 	SortBy   SortOrder `json:"sort"`
 	Tag      string    `json:"tag"`
+	Phrase   string    `json:"s"`
+	RandomID string    `json:"random_id"`
 }
 
 var errEmptyResponse = errors.New("could not parse the response from the server")
@@ -262,40 +424,46 @@ var blankGiph Giph
 
 type giphWrap struct {
 	Giph *Giph `json:"data"`
+	Meta *Meta `json:"meta,omitempty"`
 }
 
 func (c *Client) RandomSticker(ctx context.Context, req *Request) (*Giph, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).RandomStickers")
 	defer span.End()
 
-	return c.randomGIF(ctx, req, "/stickers/random")
+	return c.randomGIF(ctx, req, EndpointRandomSticker)
 }
 
 func (c *Client) RandomGIF(ctx context.Context, req *Request) (*Giph, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).RandomGIF")
 	defer span.End()
 
-	return c.randomGIF(ctx, req, "/gifs/random")
+	return c.randomGIF(ctx, req, EndpointRandom)
 }
 
-func (c *Client) randomGIF(ctx context.Context, req *Request, route string) (*Giph, error) {
+func (c *Client) randomGIF(ctx context.Context, req *Request, endpoint Endpoint) (*Giph, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).randomGIF")
 	defer span.End()
 
+	if err := req.Validate(endpoint); err != nil {
+		return nil, err
+	}
 	if req == nil {
 		req = new(Request)
 	}
+	req = c.withSafety(req)
 	pager := &pager{
-		Rating: req.Rating,
-		Format: req.Format,
-		Tag:    req.Tag,
+		Rating:   req.Rating,
+		Format:   req.Format,
+		Tag:      req.Tag,
+		RandomID: req.RandomID,
 	}
 	qv, err := otils.ToURLValues(pager)
 	if err != nil {
 		return nil, err
 	}
 	qv.Set("api_key", c._apiKey())
-	theURL := fmt.Sprintf("%s%s?%s", baseURL, route, qv.Encode())
+	theURL := fmt.Sprintf("%s%s?%s", baseURL, endpoint, qv.Encode())
 	return c.fetchGIF(ctx, theURL)
 }
 
@@ -312,15 +480,43 @@ func (c *Client) fetchGIF(ctx context.Context, theURL string) (*Giph, error) {
 		return nil, err
 	}
 	gWrap := new(giphWrap)
-	if err := json.Unmarshal(slurp, gWrap); err != nil {
+	if _, err := c.decode(theURL, slurp, gWrap); err != nil {
 		return nil, err
 	}
-	if reflect.DeepEqual(*gWrap.Giph, blankGiph) {
+	if gWrap.Giph == nil || reflect.DeepEqual(*gWrap.Giph, blankGiph) {
 		return nil, errEmptyResponse
 	}
+	if sp := c.safetyPolicy(); sp != nil && !sp.allows(gWrap.Giph) {
+		return nil, ErrBlockedBySafetyPolicy
+	}
+	gWrap.Giph.Meta = gWrap.Meta
 	return gWrap.Giph, nil
 }
 
+// Translate converts the phrase in req.Query into the single most relevant GIF.
+func (c *Client) Translate(ctx context.Context, req *Request) (*Giph, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Translate")
+	defer span.End()
+
+	if err := req.Validate(EndpointTranslate); err != nil {
+		return nil, err
+	}
+	req = c.withSafety(req)
+	pager := &pager{
+		Phrase:   req.Query,
+		Rating:   req.Rating,
+		Language: req.language(),
+		RandomID: req.RandomID,
+	}
+	qv, err := otils.ToURLValues(pager)
+	if err != nil {
+		return nil, err
+	}
+	qv.Set("api_key", c._apiKey())
+	theURL := fmt.Sprintf("%s%s?%s", baseURL, EndpointTranslate, qv.Encode())
+	return c.fetchGIF(ctx, theURL)
+}
+
 func (c *Client) GIFByID(ctx context.Context, id string) (*Giph, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).GIFByID")
 	defer span.End()
@@ -335,129 +531,196 @@ func (c *Client) Trending(ctx context.Context, req *Request) (*ResponsePager, er
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Trending")
 	defer span.End()
 
-	return c.fetch(ctx, req, "/gifs/trending")
+	return c.fetch(ctx, req, EndpointTrending)
 }
 
 func (c *Client) TrendingStickers(ctx context.Context, req *Request) (*ResponsePager, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).TrendingStickers")
 	defer span.End()
 
-	return c.fetch(ctx, req, "/stickers/trending")
+	return c.fetch(ctx, req, EndpointTrendingStickers)
 }
 
 func (c *Client) SearchStickers(ctx context.Context, req *Request) (*ResponsePager, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).SearchStickers")
 	defer span.End()
 
-	return c.fetch(ctx, req, "/stickers/search")
+	return c.fetch(ctx, req, EndpointSearchStickers)
 }
 
 func (c *Client) Search(ctx context.Context, req *Request) (*ResponsePager, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Search")
 	defer span.End()
 
-	return c.fetch(ctx, req, "/gifs/search")
+	return c.fetch(ctx, req, EndpointSearch)
 }
 
-func (c *Client) fetch(ctx context.Context, req *Request, route string) (*ResponsePager, error) {
+func (c *Client) fetch(ctx context.Context, req *Request, endpoint Endpoint) (*ResponsePager, error) {
 	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).fetch")
 	defer span.End()
 
+	if err := req.Validate(endpoint); err != nil {
+		return nil, err
+	}
 	if req == nil {
 		req = new(Request)
 	}
-
-	maxPage := req.MaxPageNumber
-	pageExceeds := func(page uint64) bool {
-		if maxPage <= 0 {
-			return false
-		}
-		return page >= maxPage
-	}
+	req = c.withSafety(req)
+	safety := c.safetyPolicy()
 
 	cancelChan, cancelFn := makeCanceler()
 	pagesChan := make(chan *Page, 1)
 
-	throttleDuration := 150 * time.Millisecond
-	if req.ThrottleDurationMs == NoThrottle {
-		throttleDuration = 0
-	} else if req.ThrottleDurationMs > 0 {
-		throttleDuration = time.Duration(req.ThrottleDurationMs) * time.Millisecond
+	if safety != nil || req.Filters != nil || req.Dedupe != nil {
+		filler := &pageFiller{client: c, req: req, endpoint: endpoint, safety: safety, cancelChan: cancelChan}
+		if req.Dedupe != nil {
+			filler.seen = req.Dedupe.seenSet()
+		}
+		go func() {
+			defer close(pagesChan)
+
+			c.paginate(ctx, req, cancelChan, func(pageNumber, offset uint64) (*Pagination, bool) {
+				page := &Page{PageNumber: pageNumber}
+				pagination, more := filler.fill(ctx, page, offset)
+				if page.Err != nil || len(page.Giphs) > 0 || page.Filtered.Total() > 0 {
+					pagesChan <- page
+				}
+				return pagination, more && page.Err == nil
+			})
+		}()
+		return &ResponsePager{Pages: pagesChan, Cancel: cancelFn}, nil
 	}
 
 	go func() {
 		defer close(pagesChan)
 
-		pageNumber := uint64(0)
-		offset := uint64(0)
-
-		for {
-			pager := &pager{
-				Limit:    req.LimitPerPage,
-				Rating:   req.Rating,
-				Format:   req.Format,
-				Offset:   offset,
-				Query:    req.Query,
-				SortBy:   req.SortBy,
-				Language: req.Language,
-			}
-
+		c.paginate(ctx, req, cancelChan, func(pageNumber, offset uint64) (*Pagination, bool) {
 			page := &Page{PageNumber: pageNumber}
-			qv, err := otils.ToURLValues(pager)
-			if err != nil {
-				page.Err = err
-				pagesChan <- page
-				return
-			}
-			qv.Set("api_key", c._apiKey())
-
-			theURL := fmt.Sprintf("%s%s?%s", baseURL, route, qv.Encode())
-			req, err := http.NewRequest("GET", theURL, nil)
-			if err != nil {
-				page.Err = err
-				pagesChan <- page
-				return
-			}
-			cctx, span := trace.StartSpan(ctx, "paging")
-			slurp, _, err := c.doHTTPReq(cctx, req)
-			span.End()
-			if err != nil {
-				page.Err = err
-				pagesChan <- page
-				return
-			}
 			res := new(Response)
-			if err := json.Unmarshal(slurp, res); err != nil {
+			warnings, err := c.getPage(ctx, string(endpoint), req.pager(offset), res)
+			page.Warnings = warnings
+			if err != nil {
 				page.Err = err
 				pagesChan <- page
-				return
+				return nil, false
 			}
 
 			if len(res.Giphs) == 0 {
 				// No more results here
-				return
+				return nil, false
+			}
+			for _, giph := range res.Giphs {
+				giph.Meta = res.Meta
 			}
 			page.Giphs = res.Giphs
+			page.Meta = res.Meta
+			page.Pagination = res.Pagination
 			pagesChan <- page
+			return res.Pagination, true
+		})
+	}()
 
-			pageNumber += 1
-			if pageExceeds(pageNumber) {
-				return
-			}
+	return &ResponsePager{Pages: pagesChan, Cancel: cancelFn}, nil
+}
 
-			select {
-			case <-cancelChan:
-				return
-			case <-time.After(throttleDuration):
-			}
+func (req *Request) pager(offset uint64) *pager {
+	return &pager{
+		Limit:    req.LimitPerPage,
+		Rating:   req.Rating,
+		Format:   req.Format,
+		Offset:   offset,
+		Query:    req.Query,
+		SortBy:   req.SortBy,
+		Language: req.language(),
+		RandomID: req.RandomID,
+	}
+}
 
-			if res.Pagination != nil {
-				offset += res.Pagination.Count
-			}
+// language returns the Language to send for req,
+// detecting it from the query if so requested.
+func (req *Request) language() Language {
+	if req.Language != "" || !req.DetectLanguage {
+		return req.Language.canonical()
+	}
+	lang, _ := DetectLanguage(req.Query)
+	return lang
+}
+
+func (req *Request) throttleDuration() time.Duration {
+	switch {
+	case req.ThrottleDurationMs == NoThrottle:
+		return 0
+	case req.ThrottleDurationMs > 0:
+		return time.Duration(req.ThrottleDurationMs) * time.Millisecond
+	default:
+		return 150 * time.Millisecond
+	}
+}
+
+// pageFetcher retrieves and delivers the page at pageNumber, returning
+// the server's pagination and whether paging should continue.
+type pageFetcher func(pageNumber, offset uint64) (*Pagination, bool)
+
+// paginate drives fetchPage until it reports no more results,
+// req.MaxPageNumber is reached or cancelChan is closed.
+func (c *Client) paginate(ctx context.Context, req *Request, cancelChan <-chan bool, fetchPage pageFetcher) {
+	maxPage := req.MaxPageNumber
+	pageExceeds := func(page uint64) bool {
+		if maxPage <= 0 {
+			return false
 		}
-	}()
+		return page >= maxPage
+	}
 
-	return &ResponsePager{Pages: pagesChan, Cancel: cancelFn}, nil
+	throttleDuration := req.throttleDuration()
+	pageNumber := uint64(0)
+	offset := uint64(0)
+
+	for {
+		pagination, more := fetchPage(pageNumber, offset)
+		if !more {
+			return
+		}
+
+		pageNumber += 1
+		if pageExceeds(pageNumber) {
+			return
+		}
+
+		select {
+		case <-cancelChan:
+			return
+		case <-time.After(throttleDuration):
+		}
+
+		if pagination != nil {
+			offset += pagination.Count
+		}
+	}
+}
+
+// getPage retrieves route with the query values of pager
+// and unmarshals the response body into save.
+func (c *Client) getPage(ctx context.Context, route string, pager interface{}, save interface{}) ([]*DecodeWarning, error) {
+	ctx, span := trace.StartSpan(ctx, "paging")
+	defer span.End()
+
+	qv, err := otils.ToURLValues(pager)
+	if err != nil {
+		return nil, err
+	}
+	qv.Set("api_key", c._apiKey())
+
+	theURL := fmt.Sprintf("%s%s?%s", baseURL, route, qv.Encode())
+	req, err := http.NewRequest("GET", theURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	slurp, _, err := c.doHTTPReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.decode(theURL, slurp, save)
 }
 
 func (c *Client) doHTTPReq(ctx context.Context, req *http.Request) ([]byte, http.Header, error) {
@@ -19,11 +19,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/orijtech/giphy/v1"
 )
@@ -125,24 +130,543 @@ func TestTrendingStickers(t *testing.T) {
 	}
 }
 
+func TestSearchClips(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: clipsRoute})
+
+	res, err := client.SearchClips(context.Background(), &giphy.Request{Query: "stranger things"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var giphs []*giphy.Giph
+	for page := range res.Pages {
+		if page.Err != nil {
+			t.Errorf("Page #%d err: %v", page.PageNumber, page.Err)
+			continue
+		}
+		giphs = append(giphs, page.Giphs...)
+	}
+	if len(giphs) != 1 {
+		t.Fatalf("gotGiphs: %d wantGiphs: 1", len(giphs))
+	}
+	video := giphs[0].Video
+	if video == nil {
+		t.Fatal("expected a non-nil Video")
+	}
+	if got, want := video.Length(), 6400*time.Millisecond; got != want {
+		t.Errorf("gotLength: %v wantLength: %v", got, want)
+	}
+	asset := video.Assets[giphy.Resolution720p]
+	if asset == nil || asset.Width != 1280 || asset.Height != 720 {
+		t.Errorf("unexpected 720p asset: %#v", asset)
+	}
+	if caption := video.Captions["en"]; caption == nil || caption.VTT == "" || caption.SRT == "" {
+		t.Errorf("unexpected english caption: %#v", caption)
+	}
+}
+
+func TestClipByID(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: clipByIDRoute})
+
+	tests := [...]struct {
+		id      string
+		wantErr bool
+	}{
+		0: {id: "lTCoC4ZuvKPDEv1dmB"},
+		1: {id: "unknown", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		giph, err := client.ClipByID(context.Background(), tt.id)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: want non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if giph.ID != tt.id {
+			t.Errorf("#%d: gotID: %q wantID: %q", i, giph.ID, tt.id)
+		}
+		if giph.Video == nil || len(giph.Video.Assets) == 0 {
+			t.Errorf("#%d: expected video assets", i)
+		}
+	}
+}
+
 func TestSearch(t *testing.T) {
-	t.Errorf("Unimplemented")
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchRoute})
+
+	tests := [...]struct {
+		req       *giphy.Request
+		wantErr   bool
+		wantPages int
+	}{
+		0: {
+			req:       &giphy.Request{Query: "hip hop", MaxPageNumber: 4, ThrottleDurationMs: giphy.NoThrottle},
+			wantPages: 4,
+		},
+		1: {
+			req:       &giphy.Request{Query: "hip hop", MaxPageNumber: 1},
+			wantPages: 1,
+		},
+	}
+
+	for i, tt := range tests {
+		res, err := client.Search(context.Background(), tt.req)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: want non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+
+		pageCount := 0
+		for page := range res.Pages {
+			if page.Err != nil {
+				t.Errorf("#%d: Page #%d err: %v", i, page.PageNumber, page.Err)
+				continue
+			}
+			pageCount += 1
+			if page.Meta == nil || page.Meta.Status != 200 || page.Meta.ResponseID == "" {
+				t.Errorf("#%d: Page #%d: unexpected meta: %#v", i, page.PageNumber, page.Meta)
+			}
+			if page.Pagination == nil || page.Pagination.Offset != 25*page.PageNumber {
+				t.Errorf("#%d: Page #%d: unexpected pagination: %#v", i, page.PageNumber, page.Pagination)
+			}
+			for _, giph := range page.Giphs {
+				if giph.Meta != page.Meta {
+					t.Errorf("#%d: Page #%d: giph %q must carry the page's meta", i, page.PageNumber, giph.ID)
+				}
+			}
+		}
+		if pageCount != tt.wantPages {
+			t.Errorf("#%d: gotPageCount: %d wantPageCount: %d", i, pageCount, tt.wantPages)
+		}
+	}
+}
+
+func TestGiphUser(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchRoute})
+
+	res, err := client.Search(context.Background(), &giphy.Request{Query: "hip hop", MaxPageNumber: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := <-res.Pages
+	res.Cancel()
+	if page == nil || page.Err != nil || len(page.Giphs) == 0 {
+		t.Fatalf("expected a non-blank first page, got %#v", page)
+	}
+	user := page.Giphs[0].User
+	want := &giphy.User{
+		AvatarURL:   "https://media1.giphy.com/avatars/joeybadass/txxpWf0aT9hF.jpg",
+		BannerURL:   "https://media1.giphy.com/headers/joeybadass/dViOmLDgpQAV.jpg",
+		ProfileURL:  "https://giphy.com/joeybadass/",
+		Username:    "joeybadass",
+		DisplayName: "Joey Bada$$",
+		Twitter:     "@joeybadass",
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("gotUser: %#v\nwantUser: %#v", user, want)
+	}
+}
+
+func TestGiphAltTextNonASCII(t *testing.T) {
+	tests := [...]struct {
+		giph *giphy.Giph
+		want string
+	}{
+		0: {giph: &giphy.Giph{ID: "x1", Slug: "élan-vital-x1"}, want: "Élan vital"},
+		1: {giph: &giphy.Giph{ID: "x2", Slug: "щенок_играет-x2"}, want: "Щенок играет"},
+		2: {giph: &giphy.Giph{ID: "x3", Slug: "שלום-עולם-x3"}, want: "שלום עולם"},
+		3: {giph: &giphy.Giph{ID: "x4", Slug: "ßtraße-x4"}, want: "ßtraße"},
+		4: {giph: &giphy.Giph{ID: "x5", Title: "über cool GIF", Slug: "uber-x5"}, want: "über cool GIF"},
+	}
+
+	for i, tt := range tests {
+		got := tt.giph.AltText()
+		if !utf8.ValidString(got) {
+			t.Errorf("#%d: %q is not valid UTF-8", i, got)
+		}
+		if got != tt.want {
+			t.Errorf("#%d: gotAltText: %q wantAltText: %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestGiphAltText(t *testing.T) {
+	giphs := loadFixtureGiphs(t, "alt-text.json")
+
+	tests := [...]struct {
+		wantAltText   string
+		wantSticker   bool
+		wantIndexable bool
+		wantCreated   time.Time
+	}{
+		0: {
+			wantAltText:   "A kitten rolls over on a rug",
+			wantIndexable: true,
+			wantCreated:   time.Date(2016, 8, 1, 16, 5, 44, 0, time.UTC),
+		},
+		1: {
+			wantAltText: "Stranger Things Sticker by NETFLIX",
+			wantSticker: true,
+		},
+		2: {wantAltText: "Harlemglobetrotters harlem globetrotters"},
+		3: {wantAltText: ""},
+	}
+
+	if len(giphs) != len(tests) {
+		t.Fatalf("gotGiphs: %d wantGiphs: %d", len(giphs), len(tests))
+	}
+	for i, tt := range tests {
+		giph := giphs[i]
+		if got := giph.AltText(); got != tt.wantAltText {
+			t.Errorf("#%d: gotAltText: %q wantAltText: %q", i, got, tt.wantAltText)
+		}
+		if got := giph.IsSticker.IsSet(); got != tt.wantSticker {
+			t.Errorf("#%d: gotSticker: %v wantSticker: %v", i, got, tt.wantSticker)
+		}
+		if got := giph.IsIndexable.IsSet(); got != tt.wantIndexable {
+			t.Errorf("#%d: gotIndexable: %v wantIndexable: %v", i, got, tt.wantIndexable)
+		}
+		var created time.Time
+		if giph.CreateDate != nil {
+			created = giph.CreateDate.Time()
+		}
+		if !created.Equal(tt.wantCreated) {
+			t.Errorf("#%d: gotCreated: %v wantCreated: %v", i, created, tt.wantCreated)
+		}
+	}
+
+	if got := giphs[0].UpdateDate.Time(); !got.Equal(time.Date(2020, 5, 12, 9, 11, 3, 0, time.UTC)) {
+		t.Errorf("unexpected update time: %v", got)
+	}
+}
+
+func TestSearchChannels(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchChannelsRoute})
+
+	res, err := client.SearchChannels(context.Background(), &giphy.Request{Query: "joey"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var channels []*giphy.Channel
+	for page := range res.Pages {
+		if page.Err != nil {
+			t.Errorf("Page #%d err: %v", page.PageNumber, page.Err)
+			continue
+		}
+		channels = append(channels, page.Channels...)
+	}
+	if len(channels) != 2 {
+		t.Fatalf("gotChannels: %d wantChannels: 2", len(channels))
+	}
+	first := channels[0]
+	if first.ID != 4384 || first.Slug != "joeybadass" {
+		t.Errorf("unexpected first channel: %#v", first)
+	}
+	if first.User == nil || !first.User.IsVerified {
+		t.Errorf("expected a verified user, got %#v", first.User)
+	}
+}
+
+func TestChannelGIFs(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: channelGIFsRoute})
+
+	tests := [...]struct {
+		username string
+		wantErr  bool
+	}{
+		0: {username: "joeybadass"},
+		1: {username: "@joeybadass"},
+		2: {username: "  ", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		res, err := client.ChannelGIFs(context.Background(), tt.username, &giphy.Request{Query: "laugh"})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: want non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		for page := range res.Pages {
+			if page.Err != nil {
+				t.Errorf("#%d: Page #%d err: %v", i, page.PageNumber, page.Err)
+			}
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: sessionRoute})
+
+	session, err := client.NewSession(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := session.RandomID(), testRandomID; got != want {
+		t.Fatalf("gotRandomID: %q wantRandomID: %q", got, want)
+	}
+
+	ctx := context.Background()
+	req := &giphy.Request{Query: "hello", MaxPageNumber: 1}
+	pagers := map[string]func() (*giphy.ResponsePager, error){
+		"search":   func() (*giphy.ResponsePager, error) { return session.Search(ctx, req) },
+		"trending": func() (*giphy.ResponsePager, error) { return session.Trending(ctx, req) },
+	}
+	for name, fn := range pagers {
+		res, err := fn()
+		if err != nil {
+			t.Errorf("%s: err: %v", name, err)
+			continue
+		}
+		for page := range res.Pages {
+			if page.Err != nil {
+				t.Errorf("%s: Page #%d err: %v", name, page.PageNumber, page.Err)
+			}
+		}
+	}
+
+	singles := map[string]func() (*giphy.Giph, error){
+		"random":    func() (*giphy.Giph, error) { return session.RandomGIF(ctx, req) },
+		"translate": func() (*giphy.Giph, error) { return session.Translate(ctx, req) },
+	}
+	for name, fn := range singles {
+		if _, err := fn(); err != nil {
+			t.Errorf("%s: err: %v", name, err)
+		}
+	}
+
+	if req.RandomID != "" {
+		t.Errorf("the session must not mutate the caller's request, got RandomID: %q", req.RandomID)
+	}
+
+	// Requests made directly through the client must not be personalized.
+	if _, err := client.RandomGIF(ctx, req); err == nil {
+		t.Errorf("expected an error for a request without a random ID")
+	}
+}
+
+func TestResumeSession(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ResumeSession("  "); err == nil {
+		t.Errorf("expected an error for a blank random ID")
+	}
+	session, err := client.ResumeSession(testRandomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := session.RandomID(); got != testRandomID {
+		t.Errorf("gotRandomID: %q wantRandomID: %q", got, testRandomID)
+	}
 }
 
 func TestSearchStickers(t *testing.T) {
-	t.Errorf("Unimplemented")
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchStickersRoute})
+
+	res, err := client.SearchStickers(context.Background(), &giphy.Request{Query: "Gotham City"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var giphs []*giphy.Giph
+	for page := range res.Pages {
+		if page.Err != nil {
+			t.Errorf("Page #%d err: %v", page.PageNumber, page.Err)
+			continue
+		}
+		giphs = append(giphs, page.Giphs...)
+	}
+	if len(giphs) != 1 {
+		t.Fatalf("gotGiphs: %d wantGiphs: 1", len(giphs))
+	}
+	if giphs[0].Images == nil || giphs[0].Images.Original == nil {
+		t.Errorf("expected the original rendition")
+	}
 }
 
 func TestGIFByID(t *testing.T) {
-	t.Errorf("Unimplemented")
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: gifByIDRoute})
+
+	tests := [...]struct {
+		id           string
+		wantErr      bool
+		wantOriginal *giphy.GIF
+	}{
+		0: {
+			id: "3ohze2UfcItWPUFqbm",
+			wantOriginal: &giphy.GIF{
+				URL:      "https://media1.giphy.com/media/3ohze2UfcItWPUFqbm/giphy.gif",
+				Width:    480,
+				Height:   270,
+				Frames:   37,
+				Size:     2001466,
+				MP4:      "https://media1.giphy.com/media/3ohze2UfcItWPUFqbm/giphy.mp4",
+				MP4Size:  268466,
+				Webp:     "https://media1.giphy.com/media/3ohze2UfcItWPUFqbm/giphy.webp",
+				WebpSize: 571534,
+			},
+		},
+		1: {id: "non-existent", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		giph, err := client.GIFByID(context.Background(), tt.id)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: want non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if giph.ID != tt.id {
+			t.Errorf("#%d: gotID: %q wantID: %q", i, giph.ID, tt.id)
+		}
+		if got := giph.Sizes["original"]; !reflect.DeepEqual(got, tt.wantOriginal) {
+			t.Errorf("#%d: gotOriginal: %#v\nwantOriginal: %#v", i, got, tt.wantOriginal)
+		}
+		wantMeta := &giphy.Meta{Status: 200, Msg: "OK", ResponseID: "5945c2b8e4d6a1f9b3c7e2a1"}
+		if !reflect.DeepEqual(giph.Meta, wantMeta) {
+			t.Errorf("#%d: gotMeta: %#v wantMeta: %#v", i, giph.Meta, wantMeta)
+		}
+	}
 }
 
 func TestRandomGIF(t *testing.T) {
-	t.Errorf("Unimplemented")
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: randomGIFRoute})
+
+	giph, err := client.RandomGIF(context.Background(), &giphy.Request{Tag: "netflix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The random endpoint only sets the flat fields,
+	// which must be normalized into Sizes and Images.
+	wantSizes := map[string]*giphy.GIF{
+		"original": {
+			URL:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/giphy.gif",
+			MP4:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/giphy.mp4",
+			Width:  480,
+			Height: 270,
+			Frames: 37,
+		},
+		"fixed_height_downsampled": {
+			URL:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/200_d.gif",
+			Width:  356,
+			Height: 200,
+		},
+		"fixed_width_downsampled": {
+			URL:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/200w_d.gif",
+			Width:  200,
+			Height: 113,
+		},
+		"fixed_height_small": {
+			URL:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/100.gif",
+			Width:  178,
+			Height: 100,
+		},
+		"fixed_height_small_still": {
+			URL:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/100_s.gif",
+			Width:  178,
+			Height: 100,
+		},
+		"fixed_width_small": {
+			URL:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/100w.gif",
+			Width:  100,
+			Height: 56,
+		},
+		"fixed_width_small_still": {
+			URL:    "https://media2.giphy.com/media/3o7btNa0RUYa5E7iiQ/100w_s.gif",
+			Width:  100,
+			Height: 56,
+		},
+	}
+	if !reflect.DeepEqual(giph.Sizes, wantSizes) {
+		t.Errorf("gotSizes: %#v\nwantSizes: %#v", giph.Sizes, wantSizes)
+	}
+	if giph.Images == nil || giph.Images.FixedWidthSmallStill != giph.Sizes["fixed_width_small_still"] {
+		t.Errorf("Images must be built from the normalized Sizes")
+	}
+	if giph.Meta == nil || giph.Meta.ResponseID != "5945c1a2d3f4e5a6b7c8d9e0" {
+		t.Errorf("unexpected meta: %#v", giph.Meta)
+	}
 }
 
 func TestRandomSticker(t *testing.T) {
-	t.Errorf("Unimplemented")
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: randomStickerRoute})
+
+	giph, err := client.RandomSticker(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if giph.Type != "sticker" {
+		t.Errorf("gotType: %q wantType: sticker", giph.Type)
+	}
+	if len(giph.Sizes) != 2 || giph.Images == nil || giph.Images.FixedWidth == nil {
+		t.Errorf("unexpected renditions: %#v", giph.Sizes)
+	}
 }
 
 type transport struct {
@@ -163,6 +687,22 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		return t.randomGIFRoundTrip(req)
 	case randomStickerRoute:
 		return t.randomStickerRoundTrip(req)
+	case searchRoute:
+		return t.searchRoundTrip(req)
+	case searchStickersRoute:
+		return t.searchStickersRoundTrip(req)
+	case searchChannelsRoute:
+		return t.searchChannelsRoundTrip(req)
+	case channelGIFsRoute:
+		return t.channelGIFsRoundTrip(req)
+	case driftRoute:
+		return t.driftRoundTrip(req)
+	case sessionRoute:
+		return t.sessionRoundTrip(req)
+	case clipsRoute:
+		return t.clipsRoundTrip(req)
+	case clipByIDRoute:
+		return t.clipByIDRoundTrip(req)
 	default:
 		return nil, errUnimplemented
 	}
@@ -172,11 +712,21 @@ const (
 	testAPIKey1 = "test-api-key1"
 	testAPIKey2 = "test-api-key2"
 
+	testRandomID = "e0771ed0a3f42b07b7ba38e40dca3c5b"
+
 	trendingRoute         = "/trending"
 	trendingStickersRoute = "/trending-stickers"
 	gifByIDRoute          = "/gif-by-id"
 	randomGIFRoute        = "/random-gif"
 	randomStickerRoute    = "/random-sticker"
+	searchRoute           = "/search"
+	searchStickersRoute   = "/search-stickers"
+	searchChannelsRoute   = "/search-channels"
+	channelGIFsRoute      = "/channel-gifs"
+	sessionRoute          = "/session"
+	driftRoute            = "/drift"
+	clipsRoute            = "/clips"
+	clipByIDRoute         = "/clip-by-id"
 )
 
 var errUnimplemented = errors.New("unimplemented")
@@ -264,19 +814,176 @@ func (t *transport) gifByIDRoundTrip(req *http.Request) (*http.Response, error)
 	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
 		return badAuthResp, err
 	}
-	return nil, errUnimplemented
+	id := path.Base(req.URL.Path)
+	f, err := os.Open(fmt.Sprintf("./testdata/gif-%s.json", id))
+	if err != nil {
+		return makeResp("404 Not Found", http.StatusNotFound, ioutil.NopCloser(strings.NewReader(err.Error()))), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
 }
 
 func (t *transport) randomGIFRoundTrip(req *http.Request) (*http.Response, error) {
 	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
 		return badAuthResp, err
 	}
-	return nil, errUnimplemented
+	f, err := os.Open("./testdata/random-gif.json")
+	if err != nil {
+		return makeResp(err.Error(), http.StatusBadRequest, nil), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
 }
 
 func (t *transport) randomStickerRoundTrip(req *http.Request) (*http.Response, error) {
 	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
 		return badAuthResp, err
 	}
-	return nil, errUnimplemented
+	f, err := os.Open("./testdata/random-sticker.json")
+	if err != nil {
+		return makeResp(err.Error(), http.StatusBadRequest, nil), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func (t *transport) searchStickersRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	query := req.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	f, err := os.Open(fmt.Sprintf("./testdata/search-stickers-%d.json", offset))
+	if err != nil {
+		return blankDataResp(), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func (t *transport) clipsRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	if offset := req.URL.Query().Get("offset"); offset != "" && offset != "0" {
+		return blankDataResp(), nil
+	}
+	f, err := os.Open("./testdata/clips-0.json")
+	if err != nil {
+		return makeResp(err.Error(), http.StatusBadRequest, nil), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func (t *transport) clipByIDRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	id := path.Base(req.URL.Path)
+	f, err := os.Open(fmt.Sprintf("./testdata/clip-%s.json", id))
+	if err != nil {
+		return makeResp("404 Not Found", http.StatusNotFound, ioutil.NopCloser(strings.NewReader(err.Error()))), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+var blankDataBody = `{"data":[]}`
+
+func blankDataResp() *http.Response {
+	return makeResp("200", http.StatusOK, ioutil.NopCloser(strings.NewReader(blankDataBody)))
+}
+
+func (t *transport) searchRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	query := req.URL.Query()
+	if strings.TrimSpace(query.Get("q")) == "" {
+		return makeResp(`expecting "q"`, http.StatusBadRequest, ioutil.NopCloser(strings.NewReader(""))), nil
+	}
+	offsetStr := query.Get("offset")
+	var offset int
+	if offsetStr != "" {
+		var err error
+		if offset, err = strconv.Atoi(offsetStr); err != nil {
+			return makeResp(fmt.Sprintf(`%q could not be parsed as "offset"`, offsetStr), http.StatusBadRequest, nil), nil
+		}
+	}
+	f, err := os.Open(fmt.Sprintf("./testdata/search-%d.json", offset/25))
+	if err != nil {
+		return blankDataResp(), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func (t *transport) searchChannelsRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	if offset := req.URL.Query().Get("offset"); offset != "" && offset != "0" {
+		return blankDataResp(), nil
+	}
+	f, err := os.Open("./testdata/channels-search-0.json")
+	if err != nil {
+		return makeResp(err.Error(), http.StatusBadRequest, nil), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func (t *transport) channelGIFsRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	query := req.URL.Query()
+	if got, want := query.Get("q"), "@joeybadass laugh"; got != want {
+		body := fmt.Sprintf("got query %q want %q", got, want)
+		return makeResp(body, http.StatusBadRequest, ioutil.NopCloser(strings.NewReader(body))), nil
+	}
+	if offset := query.Get("offset"); offset != "" && offset != "0" {
+		return blankDataResp(), nil
+	}
+	f, err := os.Open("./testdata/search-stickers-0.json")
+	if err != nil {
+		return makeResp(err.Error(), http.StatusBadRequest, nil), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func (t *transport) sessionRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	if strings.HasSuffix(req.URL.Path, "/randomid") {
+		body := fmt.Sprintf(`{"data":{"random_id":%q},"meta":{"status":200,"msg":"OK"}}`, testRandomID)
+		return makeResp("200", http.StatusOK, ioutil.NopCloser(strings.NewReader(body))), nil
+	}
+	query := req.URL.Query()
+	if got := query.Get("random_id"); got != testRandomID {
+		body := fmt.Sprintf("got random_id %q want %q", got, testRandomID)
+		return makeResp(body, http.StatusBadRequest, ioutil.NopCloser(strings.NewReader(body))), nil
+	}
+	var srcPath string
+	switch path.Base(req.URL.Path) {
+	case "search", "trending":
+		srcPath = "./testdata/search-stickers-0.json"
+	case "random", "translate":
+		srcPath = "./testdata/random-gif.json"
+	default:
+		return makeResp(req.URL.Path, http.StatusNotFound, ioutil.NopCloser(strings.NewReader(""))), nil
+	}
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return makeResp(err.Error(), http.StatusBadRequest, nil), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func (t *transport) driftRoundTrip(req *http.Request) (*http.Response, error) {
+	if badAuthResp, err := checkBadAuthAndMethod(req, "GET"); badAuthResp != nil || err != nil {
+		return badAuthResp, err
+	}
+	if offset := req.URL.Query().Get("offset"); offset != "" && offset != "0" {
+		return blankDataResp(), nil
+	}
+	f, err := os.Open("./testdata/drift-0.json")
+	if err != nil {
+		return makeResp(err.Error(), http.StatusBadRequest, nil), nil
+	}
+	return makeResp("200", http.StatusOK, f), nil
 }
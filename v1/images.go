@@ -0,0 +1,288 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"encoding/json"
+)
+
+// Rendition is the name of one of the sizes
+// that GIPHY renders every Giph in.
+type Rendition string
+
+const (
+	RenditionOriginal      Rendition = "original"
+	RenditionOriginalStill Rendition = "original_still"
+	RenditionOriginalMP4   Rendition = "original_mp4"
+
+	RenditionFixedHeight            Rendition = "fixed_height"
+	RenditionFixedHeightStill       Rendition = "fixed_height_still"
+	RenditionFixedHeightDownsampled Rendition = "fixed_height_downsampled"
+	RenditionFixedHeightSmall       Rendition = "fixed_height_small"
+	RenditionFixedHeightSmallStill  Rendition = "fixed_height_small_still"
+
+	RenditionFixedWidth            Rendition = "fixed_width"
+	RenditionFixedWidthStill       Rendition = "fixed_width_still"
+	RenditionFixedWidthDownsampled Rendition = "fixed_width_downsampled"
+	RenditionFixedWidthSmall       Rendition = "fixed_width_small"
+	RenditionFixedWidthSmallStill  Rendition = "fixed_width_small_still"
+
+	RenditionDownsized       Rendition = "downsized"
+	RenditionDownsizedStill  Rendition = "downsized_still"
+	RenditionDownsizedLarge  Rendition = "downsized_large"
+	RenditionDownsizedMedium Rendition = "downsized_medium"
+	RenditionDownsizedSmall  Rendition = "downsized_small"
+
+	RenditionPreview     Rendition = "preview"
+	RenditionPreviewGIF  Rendition = "preview_gif"
+	RenditionPreviewWebp Rendition = "preview_webp"
+
+	RenditionLooping Rendition = "looping"
+
+	Rendition480wStill Rendition = "480w_still"
+	RenditionHD        Rendition = "hd"
+	Rendition4K        Rendition = "4k"
+)
+
+// KnownRenditions lists every rendition that Images has a field for.
+var KnownRenditions = [...]Rendition{
+	RenditionOriginal, RenditionOriginalStill, RenditionOriginalMP4,
+
+	RenditionFixedHeight, RenditionFixedHeightStill, RenditionFixedHeightDownsampled,
+	RenditionFixedHeightSmall, RenditionFixedHeightSmallStill,
+
+	RenditionFixedWidth, RenditionFixedWidthStill, RenditionFixedWidthDownsampled,
+	RenditionFixedWidthSmall, RenditionFixedWidthSmallStill,
+
+	RenditionDownsized, RenditionDownsizedStill, RenditionDownsizedLarge,
+	RenditionDownsizedMedium, RenditionDownsizedSmall,
+
+	RenditionPreview, RenditionPreviewGIF, RenditionPreviewWebp,
+
+	RenditionLooping,
+
+	Rendition480wStill, RenditionHD, Rendition4K,
+}
+
+func (r Rendition) IsKnown() bool {
+	for _, known := range KnownRenditions {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Images is the typed counterpart of Giph.Sizes.
+type Images struct {
+	Original      *GIF `json:"original,omitempty"`
+	OriginalStill *GIF `json:"original_still,omitempty"`
+	OriginalMP4   *GIF `json:"original_mp4,omitempty"`
+
+	FixedHeight            *GIF `json:"fixed_height,omitempty"`
+	FixedHeightStill       *GIF `json:"fixed_height_still,omitempty"`
+	FixedHeightDownsampled *GIF `json:"fixed_height_downsampled,omitempty"`
+	FixedHeightSmall       *GIF `json:"fixed_height_small,omitempty"`
+	FixedHeightSmallStill  *GIF `json:"fixed_height_small_still,omitempty"`
+
+	FixedWidth            *GIF `json:"fixed_width,omitempty"`
+	FixedWidthStill       *GIF `json:"fixed_width_still,omitempty"`
+	FixedWidthDownsampled *GIF `json:"fixed_width_downsampled,omitempty"`
+	FixedWidthSmall       *GIF `json:"fixed_width_small,omitempty"`
+	FixedWidthSmallStill  *GIF `json:"fixed_width_small_still,omitempty"`
+
+	Downsized       *GIF `json:"downsized,omitempty"`
+	DownsizedStill  *GIF `json:"downsized_still,omitempty"`
+	DownsizedLarge  *GIF `json:"downsized_large,omitempty"`
+	DownsizedMedium *GIF `json:"downsized_medium,omitempty"`
+	DownsizedSmall  *GIF `json:"downsized_small,omitempty"`
+
+	Preview     *GIF `json:"preview,omitempty"`
+	PreviewGIF  *GIF `json:"preview_gif,omitempty"`
+	PreviewWebp *GIF `json:"preview_webp,omitempty"`
+
+	Looping *GIF `json:"looping,omitempty"`
+
+	Width480Still *GIF `json:"480w_still,omitempty"`
+	HD            *GIF `json:"hd,omitempty"`
+	FourK         *GIF `json:"4k,omitempty"`
+}
+
+func (im *Images) field(r Rendition) **GIF {
+	switch r {
+	case RenditionOriginal:
+		return &im.Original
+	case RenditionOriginalStill:
+		return &im.OriginalStill
+	case RenditionOriginalMP4:
+		return &im.OriginalMP4
+	case RenditionFixedHeight:
+		return &im.FixedHeight
+	case RenditionFixedHeightStill:
+		return &im.FixedHeightStill
+	case RenditionFixedHeightDownsampled:
+		return &im.FixedHeightDownsampled
+	case RenditionFixedHeightSmall:
+		return &im.FixedHeightSmall
+	case RenditionFixedHeightSmallStill:
+		return &im.FixedHeightSmallStill
+	case RenditionFixedWidth:
+		return &im.FixedWidth
+	case RenditionFixedWidthStill:
+		return &im.FixedWidthStill
+	case RenditionFixedWidthDownsampled:
+		return &im.FixedWidthDownsampled
+	case RenditionFixedWidthSmall:
+		return &im.FixedWidthSmall
+	case RenditionFixedWidthSmallStill:
+		return &im.FixedWidthSmallStill
+	case RenditionDownsized:
+		return &im.Downsized
+	case RenditionDownsizedStill:
+		return &im.DownsizedStill
+	case RenditionDownsizedLarge:
+		return &im.DownsizedLarge
+	case RenditionDownsizedMedium:
+		return &im.DownsizedMedium
+	case RenditionDownsizedSmall:
+		return &im.DownsizedSmall
+	case RenditionPreview:
+		return &im.Preview
+	case RenditionPreviewGIF:
+		return &im.PreviewGIF
+	case RenditionPreviewWebp:
+		return &im.PreviewWebp
+	case RenditionLooping:
+		return &im.Looping
+	case Rendition480wStill:
+		return &im.Width480Still
+	case RenditionHD:
+		return &im.HD
+	case Rendition4K:
+		return &im.FourK
+	default:
+		return nil
+	}
+}
+
+// Get returns the GIF for rendition r or nil if it is unknown or absent.
+func (im *Images) Get(r Rendition) *GIF {
+	if im == nil {
+		return nil
+	}
+	if ptr := im.field(r); ptr != nil {
+		return *ptr
+	}
+	return nil
+}
+
+func imagesFromSizes(sizes map[string]*GIF) *Images {
+	if len(sizes) == 0 {
+		return nil
+	}
+	im := new(Images)
+	for name, gif := range sizes {
+		if ptr := im.field(Rendition(name)); ptr != nil {
+			*ptr = gif
+		}
+	}
+	return im
+}
+
+// Rendition returns the GIF for rendition r, looking it up in
+// Sizes so that renditions unknown to Images are also reachable.
+func (g *Giph) Rendition(r Rendition) *GIF {
+	if g == nil {
+		return nil
+	}
+	return g.Sizes[string(r)]
+}
+
+// normalize fills in Sizes from the flat fields that only the legacy
+// random endpoint sets, so that every Giph exposes its renditions the
+// same way. Renditions that the server already put in Sizes are kept.
+func (g *Giph) normalize() {
+	flat := [...]struct {
+		rendition     Rendition
+		url, mp4      string
+		width, height int
+		frames        uint
+	}{
+		{RenditionOriginal, firstNonBlank(g.ImageOriginalURL, g.ImageURL), g.ImageMP4URL, g.ImageWidth, g.ImageHeight, g.FrameCount},
+		{RenditionFixedHeightDownsampled, g.FixedHeightDownsampledURL, "", g.FixedHeightDownsampledWidth, g.FixedHeightDownsampledHeight, 0},
+		{RenditionFixedHeightSmall, g.FixedHeightSmallURL, "", g.FixedHeightSmallWidth, g.FixedHeightSmallHeight, 0},
+		{
+			RenditionFixedHeightSmallStill, g.FixedHeightSmallStillURL, "",
+			firstNonZero(g.FixedHeightSmallStillWidth, g.FixedHeightSmallWidth),
+			firstNonZero(g.FixedHeightSmallStillHeight, g.FixedHeightSmallHeight), 0,
+		},
+		{RenditionFixedWidthDownsampled, g.FixedWidthDownsampledURL, "", g.FixedWidthDownsampledWidth, g.FixedWidthDownsampledHeight, 0},
+		{RenditionFixedWidthSmall, g.FixedWidthSmallURL, "", g.FixedWidthSmallWidth, g.FixedWidthSmallHeight, 0},
+		{
+			RenditionFixedWidthSmallStill, g.FixedWidthSmallStillURL, "",
+			firstNonZero(g.FixedWidthSmallStillWidth, g.FixedWidthSmallWidth),
+			firstNonZero(g.FixedWidthSmallStillHeight, g.FixedWidthSmallHeight), 0,
+		},
+	}
+
+	for _, f := range flat {
+		if f.url == "" && f.mp4 == "" {
+			continue
+		}
+		if _, ok := g.Sizes[string(f.rendition)]; ok {
+			continue
+		}
+		if g.Sizes == nil {
+			g.Sizes = make(map[string]*GIF)
+		}
+		g.Sizes[string(f.rendition)] = &GIF{
+			URL:    f.url,
+			MP4:    f.mp4,
+			Width:  f.width,
+			Height: f.height,
+			Frames: f.frames,
+		}
+	}
+
+	g.Images = imagesFromSizes(g.Sizes)
+}
+
+func firstNonBlank(strs ...string) string {
+	for _, str := range strs {
+		if str != "" {
+			return str
+		}
+	}
+	return ""
+}
+
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+type giphAlias Giph
+
+func (g *Giph) UnmarshalJSON(b []byte) error {
+	err := json.Unmarshal(b, (*giphAlias)(g))
+	// Type mismatches still leave the rest of g decoded,
+	// so normalize regardless for strict decoding's sake.
+	g.normalize()
+	return err
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func loadFixtureGiphs(t *testing.T, pattern string) []*giphy.Giph {
+	paths, err := filepath.Glob(filepath.Join("./testdata", pattern))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var giphs []*giphy.Giph
+	for _, path := range paths {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := new(giphy.Response)
+		if err := json.Unmarshal(blob, res); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		giphs = append(giphs, res.Giphs...)
+	}
+	return giphs
+}
+
+func TestImagesMatchSizes(t *testing.T) {
+	giphs := loadFixtureGiphs(t, "trending-*.json")
+	giphs = append(giphs, loadFixtureGiphs(t, "search-*.json")...)
+	if len(giphs) == 0 {
+		t.Fatal("expected giphs from the fixtures")
+	}
+
+	for i, giph := range giphs {
+		if giph.Images == nil {
+			t.Errorf("#%d (%s): expected non-nil Images", i, giph.ID)
+			continue
+		}
+		for name, gif := range giph.Sizes {
+			rendition := giphy.Rendition(name)
+			if !rendition.IsKnown() {
+				t.Errorf("#%d (%s): unknown rendition %q", i, giph.ID, name)
+				continue
+			}
+			if got := giph.Images.Get(rendition); got != gif {
+				t.Errorf("#%d (%s): %q: gotGIF: %#v wantGIF: %#v", i, giph.ID, name, got, gif)
+			}
+		}
+		if giph.Images.FixedHeightStill != giph.Sizes["fixed_height_still"] {
+			t.Errorf("#%d (%s): FixedHeightStill does not match Sizes", i, giph.ID)
+		}
+		if giph.Rendition(giphy.RenditionOriginal) != giph.Images.Original {
+			t.Errorf("#%d (%s): Rendition(original) does not match Images.Original", i, giph.ID)
+		}
+	}
+}
+
+func TestImagesGet(t *testing.T) {
+	var nilImages *giphy.Images
+	if got := nilImages.Get(giphy.RenditionOriginal); got != nil {
+		t.Errorf("nil Images: gotGIF: %#v", got)
+	}
+
+	original := &giphy.GIF{URL: "https://media.giphy.com/media/x/giphy.gif"}
+	images := &giphy.Images{Original: original}
+	if got := images.Get(giphy.RenditionOriginal); got != original {
+		t.Errorf("gotGIF: %#v wantGIF: %#v", got, original)
+	}
+	if got := images.Get(giphy.Rendition("not_a_rendition")); got != nil {
+		t.Errorf("unknown rendition: gotGIF: %#v", got)
+	}
+	for _, rendition := range giphy.KnownRenditions {
+		if !rendition.IsKnown() {
+			t.Errorf("%q must be known", rendition)
+		}
+	}
+}
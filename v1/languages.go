@@ -14,9 +14,18 @@
 
 package giphy
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
 type Language string
 
 const (
+	LangEnglish            Language = "en"
 	LangSpanish            Language = "es"
 	LangPortuguese         Language = "pt"
 	LangIndonesian         Language = "id"
@@ -44,8 +53,161 @@ const (
 	LangFarsi              Language = "fa"
 	LangFilipino           Language = "tl"
 	LangFinnish            Language = "fi"
-	LangHebrew             Language = "iw"
+	LangHebrew             Language = "he"
 	LangMalay              Language = "ms"
 	LangNorwegian          Language = "no"
 	LangUkrainian          Language = "uk"
 )
+
+type LanguageInfo struct {
+	Code        Language     `json:"code"`
+	EnglishName string       `json:"english_name"`
+	NativeName  string       `json:"native_name"`
+	Tag         language.Tag `json:"-"`
+}
+
+// languageRegistry lists every language that GIPHY supports. English
+// comes first since it is what GIPHY falls back to for unmatched input.
+var languageRegistry = [...]*LanguageInfo{
+	{LangEnglish, "English", "English", language.English},
+	{LangSpanish, "Spanish", "Español", language.Spanish},
+	{LangPortuguese, "Portuguese", "Português", language.Portuguese},
+	{LangIndonesian, "Indonesian", "Bahasa Indonesia", language.Indonesian},
+	{LangFrench, "French", "Français", language.French},
+	{LangArabic, "Arabic", "العربية", language.Arabic},
+	{LangTurkish, "Turkish", "Türkçe", language.Turkish},
+	{LangThai, "Thai", "ไทย", language.Thai},
+	{LangVietnamese, "Vietnamese", "Tiếng Việt", language.Vietnamese},
+	{LangGerman, "German", "Deutsch", language.German},
+	{LangItalian, "Italian", "Italiano", language.Italian},
+	{LangJapanese, "Japanese", "日本語", language.Japanese},
+	{LangChineseSimplified, "Chinese (Simplified)", "简体中文", language.SimplifiedChinese},
+	{LangChineseTraditional, "Chinese (Traditional)", "繁體中文", language.TraditionalChinese},
+	{LangRussian, "Russian", "Русский", language.Russian},
+	{LangKorean, "Korean", "한국어", language.Korean},
+	{LangPolish, "Polish", "Polski", language.Polish},
+	{LangDutch, "Dutch", "Nederlands", language.Dutch},
+	{LangRomanian, "Romanian", "Română", language.Romanian},
+	{LangHungarian, "Hungarian", "Magyar", language.Hungarian},
+	{LangSwedish, "Swedish", "Svenska", language.Swedish},
+	{LangCzech, "Czech", "Čeština", language.Czech},
+	{LangHindi, "Hindi", "हिन्दी", language.Hindi},
+	{LangBengali, "Bengali", "বাংলা", language.Bengali},
+	{LangDanish, "Danish", "Dansk", language.Danish},
+	{LangFarsi, "Farsi", "فارسی", language.Persian},
+	{LangFilipino, "Filipino", "Filipino", language.Filipino},
+	{LangFinnish, "Finnish", "Suomi", language.Finnish},
+	{LangHebrew, "Hebrew", "עברית", language.Hebrew},
+	{LangMalay, "Malay", "Bahasa Melayu", language.Malay},
+	{LangNorwegian, "Norwegian", "Norsk", language.MustParse("nb")},
+	{LangUkrainian, "Ukrainian", "Українська", language.Ukrainian},
+}
+
+// languageAliases maps the codes that GIPHY used to document, and that
+// callers may still send, to the codes that replaced them.
+var languageAliases = map[Language]Language{
+	// The deprecated ISO 639 code of Hebrew.
+	"iw": LangHebrew,
+}
+
+// canonical resolves l if it is an alias of a supported Language.
+func (l Language) canonical() Language {
+	if code, ok := languageAliases[l]; ok {
+		return code
+	}
+	return l
+}
+
+var languageMatcher = func() language.Matcher {
+	tags := make([]language.Tag, 0, len(languageRegistry))
+	for _, info := range languageRegistry {
+		tags = append(tags, info.Tag)
+	}
+	return language.NewMatcher(tags)
+}()
+
+// Languages returns the registry of the languages that GIPHY supports.
+func Languages() []*LanguageInfo {
+	infos := make([]*LanguageInfo, 0, len(languageRegistry))
+	for _, info := range languageRegistry {
+		copied := *info
+		infos = append(infos, &copied)
+	}
+	return infos
+}
+
+func (l Language) info() *LanguageInfo {
+	l = l.canonical()
+	for _, info := range languageRegistry {
+		if info.Code == l {
+			return info
+		}
+	}
+	return nil
+}
+
+func (l Language) IsSupported() bool {
+	return l.info() != nil
+}
+
+func (l Language) EnglishName() string {
+	if info := l.info(); info != nil {
+		return info.EnglishName
+	}
+	return ""
+}
+
+func (l Language) NativeName() string {
+	if info := l.info(); info != nil {
+		return info.NativeName
+	}
+	return ""
+}
+
+var errBlankLanguage = errors.New("expecting a non-blank language")
+
+// ParseLanguage picks the supported Language that best matches s,
+// which is either a BCP 47 tag such as "pt-BR", a GIPHY language code
+// or the value of an Accept-Language header like "fr-CH, fr;q=0.9, en;q=0.8".
+func ParseLanguage(s string) (Language, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errBlankLanguage
+	}
+	for _, info := range languageRegistry {
+		if strings.EqualFold(s, string(info.Code)) {
+			return info.Code, nil
+		}
+	}
+	for alias, code := range languageAliases {
+		if strings.EqualFold(s, string(alias)) {
+			return code, nil
+		}
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(s)
+	if err != nil || len(tags) == 0 {
+		tag, perr := language.Parse(s)
+		if perr != nil {
+			return "", fmt.Errorf("%q is neither a language tag nor an Accept-Language header: %v", s, perr)
+		}
+		tags = []language.Tag{tag}
+	}
+
+	_, index, confidence := languageMatcher.Match(tags...)
+	if confidence == language.No || (index == 0 && !anyEnglish(tags)) {
+		// The matcher falls back to English, the first entry,
+		// for languages it cannot otherwise match.
+		return "", fmt.Errorf("%q does not match any language that GIPHY supports", s)
+	}
+	return languageRegistry[index].Code, nil
+}
+
+func anyEnglish(tags []language.Tag) bool {
+	for _, tag := range tags {
+		if base, _ := tag.Base(); base.String() == "en" {
+			return true
+		}
+	}
+	return false
+}
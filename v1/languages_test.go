@@ -0,0 +1,137 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := [...]struct {
+		in      string
+		want    giphy.Language
+		wantErr bool
+	}{
+		0:  {in: "en", want: giphy.LangEnglish},
+		1:  {in: " zh-cn ", want: giphy.LangChineseSimplified},
+		2:  {in: "en-US", want: giphy.LangEnglish},
+		3:  {in: "pt-BR", want: giphy.LangPortuguese},
+		4:  {in: "zh-Hant", want: giphy.LangChineseTraditional},
+		5:  {in: "zh-HK", want: giphy.LangChineseTraditional},
+		6:  {in: "zh", want: giphy.LangChineseSimplified},
+		7:  {in: "iw", want: giphy.LangHebrew},
+		8:  {in: "he-IL", want: giphy.LangHebrew},
+		9:  {in: "nb", want: giphy.LangNorwegian},
+		10: {in: "fil", want: giphy.LangFilipino},
+		11: {in: "fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", want: giphy.LangFrench},
+		12: {in: "sw, de;q=0.5", want: giphy.LangGerman},
+		13: {in: "", wantErr: true},
+		14: {in: "sw", wantErr: true},
+		15: {in: "zz-ZZ", wantErr: true},
+		16: {in: "*", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		got, err := giphy.ParseLanguage(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: %q: expected a non-nil error, got %q", i, tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: %q: unexpected err: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("#%d: %q: got=%q want=%q", i, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLanguageRegistry(t *testing.T) {
+	langs := giphy.Languages()
+	if len(langs) != 32 {
+		t.Errorf("got %d languages want 32", len(langs))
+	}
+	seen := make(map[giphy.Language]bool)
+	for _, info := range langs {
+		if seen[info.Code] {
+			t.Errorf("%q is registered more than once", info.Code)
+		}
+		seen[info.Code] = true
+		if !info.Code.IsSupported() {
+			t.Errorf("%q: expected to be supported", info.Code)
+		}
+		if info.EnglishName == "" || info.NativeName == "" {
+			t.Errorf("%q: blank names %+v", info.Code, info)
+		}
+		// Every registered code must parse back to itself.
+		if got, err := giphy.ParseLanguage(string(info.Code)); err != nil || got != info.Code {
+			t.Errorf("%q: ParseLanguage got=%q err=%v", info.Code, got, err)
+		}
+	}
+
+	// Mutating the returned registry must not affect the package's copy.
+	langs[0].EnglishName = "Mutated"
+	if got := giphy.LangEnglish.EnglishName(); got != "English" {
+		t.Errorf("EnglishName: got=%q want=%q", got, "English")
+	}
+	if got, want := giphy.LangJapanese.NativeName(), "日本語"; got != want {
+		t.Errorf("NativeName: got=%q want=%q", got, want)
+	}
+	for _, lang := range []giphy.Language{"", "xx", "EN"} {
+		if lang.IsSupported() {
+			t.Errorf("%q: expected to be unsupported", lang)
+		}
+	}
+}
+
+func TestLanguageAliases(t *testing.T) {
+	const iw = giphy.Language("iw")
+	if !iw.IsSupported() {
+		t.Errorf("%q: expected to be supported", iw)
+	}
+	if got, want := iw.EnglishName(), "Hebrew"; got != want {
+		t.Errorf("EnglishName: got=%q want=%q", got, want)
+	}
+	if got, err := giphy.ParseLanguage("IW"); err != nil || got != giphy.LangHebrew {
+		t.Errorf("ParseLanguage: got=%q err=%v want=%q", got, err, giphy.LangHebrew)
+	}
+	if err := (&giphy.Request{Query: "shalom", Language: iw}).Validate(giphy.EndpointSearch); err != nil {
+		t.Errorf("Validate: unexpected err: %v", err)
+	}
+
+	// The alias is sent to GIPHY as the canonical code.
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := new(langRecorder)
+	client.SetHTTPRoundTripper(recorder)
+	res, err := client.Search(context.Background(), &giphy.Request{Query: "shalom", Language: iw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range res.Pages {
+	}
+	if want := []string{"he"}; !reflect.DeepEqual(recorder.langs, want) {
+		t.Errorf("got langs=%q want=%q", recorder.langs, want)
+	}
+}
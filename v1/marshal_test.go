@@ -0,0 +1,181 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestGiphyTimeMarshalJSON(t *testing.T) {
+	tests := [...]struct {
+		gt      *giphy.GiphyTime
+		want    string
+		wantErr bool
+	}{
+		0: {gt: new(giphy.GiphyTime), want: `"0000-00-00 00:00:00"`},
+		1: {gt: giphy.NewGiphyTime(time.Date(2017, 4, 5, 15, 46, 13, 0, time.UTC)), want: `"2017-04-05 15:46:13"`},
+		// GIPHY's times carry no zone and are read back as UTC.
+		2: {gt: giphy.NewGiphyTime(time.Date(2017, 4, 5, 20, 46, 13, 0, time.FixedZone("x", 5*3600))), want: `"2017-04-05 15:46:13"`},
+	}
+
+	for i, tt := range tests {
+		blob, err := json.Marshal(tt.gt)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: want non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if got := string(blob); got != tt.want {
+			t.Errorf("#%d: got: %s want: %s", i, got, tt.want)
+		}
+	}
+}
+
+func TestGiphyTimeUnmarshalJSON(t *testing.T) {
+	tests := [...]struct {
+		in       string
+		want     time.Time
+		wantZero bool
+		wantErr  bool
+	}{
+		0: {in: `"0000-00-00 00:00:00"`, wantZero: true},
+		1: {in: `""`, wantZero: true},
+		2: {in: `"2017-04-26 18:15:01"`, want: time.Date(2017, 4, 26, 18, 15, 1, 0, time.UTC)},
+		3: {in: `"yesterday"`, wantErr: true},
+		4: {in: `1493230501`, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		// Start off with a non-zero time to ensure that
+		// the zero sentinel resets any previous value.
+		gt := giphy.NewGiphyTime(time.Now())
+		err := json.Unmarshal([]byte(tt.in), gt)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: want non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if tt.wantZero {
+			if !gt.IsZero() {
+				t.Errorf("#%d: expected the zero time, got %v", i, gt.Time())
+			}
+			continue
+		}
+		if got := gt.Time(); !got.Equal(tt.want) {
+			t.Errorf("#%d: got: %v want: %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestGiphJSONRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob("./testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		var giphs []*giphy.Giph
+		res := new(giphy.Response)
+		if err := json.Unmarshal(blob, res); err == nil {
+			giphs = res.Giphs
+		} else {
+			single := new(struct {
+				Giph *giphy.Giph `json:"data"`
+			})
+			if err := json.Unmarshal(blob, single); err != nil {
+				// Neither a page of giphs nor a single giph e.g. channels.
+				continue
+			}
+			giphs = append(giphs, single.Giph)
+		}
+
+		for i, giph := range giphs {
+			if giph == nil {
+				continue
+			}
+			marshaled, err := json.Marshal(giph)
+			if err != nil {
+				t.Errorf("%s: #%d: marshal err: %v", path, i, err)
+				continue
+			}
+			reread := new(giphy.Giph)
+			if err := json.Unmarshal(marshaled, reread); err != nil {
+				t.Errorf("%s: #%d: unmarshal err: %v", path, i, err)
+				continue
+			}
+			if !reflect.DeepEqual(reread, giph) {
+				t.Errorf("%s: #%d (%s): the round trip changed the giph\ngot:  %#v\nwant: %#v", path, i, giph.ID, reread, giph)
+			}
+		}
+	}
+}
+
+func TestGiphJSONRoundTripMeta(t *testing.T) {
+	giph := &giphy.Giph{
+		ID:   "xT9IgG50Fb7Mi0prBC",
+		Meta: &giphy.Meta{Status: 200, Msg: "OK", ResponseID: "5945c2b8e4d6a1f9b3c7e2a1"},
+	}
+	marshaled, err := json.Marshal(giph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reread := new(giphy.Giph)
+	if err := json.Unmarshal(marshaled, reread); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reread.Meta, giph.Meta) {
+		t.Errorf("gotMeta: %#v wantMeta: %#v", reread.Meta, giph.Meta)
+	}
+}
+
+func TestGiphyTimeRoundTripZone(t *testing.T) {
+	want := time.Date(2017, 4, 5, 20, 46, 13, 0, time.FixedZone("x", 5*3600))
+	blob, err := json.Marshal(giphy.NewGiphyTime(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := new(giphy.GiphyTime)
+	if err := json.Unmarshal(blob, got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Time().Equal(want) {
+		t.Errorf("got: %v want: %v", got.Time(), want)
+	}
+	if gotStr := giphy.NewGiphyTime(want).String(); gotStr != "2017-04-05 15:46:13" {
+		t.Errorf("gotString: %q", gotStr)
+	}
+}
@@ -0,0 +1,361 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+
+	"go.opencensus.io/trace"
+)
+
+// PerceptualHash is a 64 bit fingerprint of how an image looks, such
+// that resized or re-encoded copies hash to nearby values.
+type PerceptualHash uint64
+
+// Distance is the number of bits that differ between h and other,
+// from 0 for look-alikes up to 64.
+func (h PerceptualHash) Distance(other PerceptualHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+func (h PerceptualHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+type HashAlgorithm int
+
+const (
+	// HashDifference (dHash) compares the brightness of neighboring
+	// pixels. It is fast and tolerant of scaling and color shifts.
+	HashDifference HashAlgorithm = iota
+	// HashDCT (pHash) keeps the signs of the low frequencies of the
+	// image, which also withstands heavier compression and blurring.
+	HashDCT
+)
+
+// DefaultHashThreshold is the Distance up to which
+// two hashes are considered to be of the same image.
+const DefaultHashThreshold = 10
+
+// Hash computes the perceptual hash of img with algo.
+func Hash(img image.Image, algo HashAlgorithm) (PerceptualHash, error) {
+	switch algo {
+	case HashDifference:
+		return dHash(img), nil
+	case HashDCT:
+		return pHash(img), nil
+	default:
+		return 0, fmt.Errorf("unknown hash algorithm %d", algo)
+	}
+}
+
+// grayscale resizes img to width by height and returns
+// the luma of its pixels, as if composited over white.
+func grayscale(img image.Image, width, height int) []float64 {
+	small := Resize(img, width, height)
+	lumas := make([]float64, 0, width*height)
+	for i := 0; i < len(small.Pix); i += 4 {
+		px := small.Pix[i : i+4]
+		luma := 0.299*float64(px[0]) + 0.587*float64(px[1]) + 0.114*float64(px[2])
+		lumas = append(lumas, luma+float64(255-px[3]))
+	}
+	return lumas
+}
+
+func dHash(img image.Image) PerceptualHash {
+	lumas := grayscale(img, 9, 8)
+	var h PerceptualHash
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if lumas[y*9+x] < lumas[y*9+x+1] {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+const dctSize = 32
+
+// dctCosines[u][x] is the DCT-II basis function u sampled at x.
+var dctCosines = func() (cosines [dctSize][dctSize]float64) {
+	for u := range cosines {
+		for x := range cosines[u] {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * dctSize))
+		}
+	}
+	return cosines
+}()
+
+func pHash(img image.Image) PerceptualHash {
+	lumas := grayscale(img, dctSize, dctSize)
+
+	// Only the 8x8 lowest frequencies are needed, computed
+	// separably: first along the rows, then down the columns.
+	var rows [dctSize][8]float64
+	for y := 0; y < dctSize; y++ {
+		for u := 0; u < 8; u++ {
+			for x := 0; x < dctSize; x++ {
+				rows[y][u] += lumas[y*dctSize+x] * dctCosines[u][x]
+			}
+		}
+	}
+	var coefficients [64]float64
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+			for y := 0; y < dctSize; y++ {
+				sum += rows[y][u] * dctCosines[v][y]
+			}
+			coefficients[v*8+u] = sum
+		}
+	}
+
+	// The DC term is the mean brightness, which says nothing about
+	// the structure and would skew the median, so leave it out.
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var h PerceptualHash
+	for _, coefficient := range coefficients {
+		h <<= 1
+		if coefficient > median {
+			h |= 1
+		}
+	}
+	return h
+}
+
+// HashFrames hashes up to samples frames of g, evenly spaced from the
+// first one, as they are displayed. Comparing these rather than a
+// single frame tells apart clips that merely open the same way.
+func HashFrames(g *gif.GIF, algo HashAlgorithm, samples int) ([]PerceptualHash, error) {
+	if g == nil || len(g.Image) == 0 {
+		return nil, errNoFrames
+	}
+	if samples <= 0 || samples > len(g.Image) {
+		samples = len(g.Image)
+	}
+
+	hashes := make([]PerceptualHash, 0, samples)
+	var err error
+	compositeFrames(g, func(i int, canvas *image.RGBA) bool {
+		// Frame i is the sample whose evenly spaced position it is.
+		if i != len(hashes)*len(g.Image)/samples {
+			return true
+		}
+		var h PerceptualHash
+		if h, err = Hash(canvas, algo); err != nil {
+			return false
+		}
+		hashes = append(hashes, h)
+		return len(hashes) < samples
+	})
+	return hashes, err
+}
+
+// SequenceDistance is the mean Distance between the hashes
+// of the corresponding frames of two HashFrames results.
+func SequenceDistance(a, b []PerceptualHash) float64 {
+	n := min(len(a), len(b))
+	if n == 0 {
+		return 64
+	}
+	total := 0
+	for i := 0; i < n; i++ {
+		total += a[i].Distance(b[i])
+	}
+	return float64(total) / float64(n)
+}
+
+type HashOptions struct {
+	Algorithm HashAlgorithm `json:"algorithm,omitempty"`
+
+	// Rendition is the one hashed, defaulting to the smallest of
+	// fixed_width_small, fixed_width and original that the Giph has.
+	// Its still counterpart is used when present.
+	Rendition Rendition `json:"rendition,omitempty"`
+}
+
+var hashRenditions = [...]Rendition{RenditionFixedWidthSmall, RenditionFixedWidth, RenditionOriginal}
+
+func (ho *HashOptions) rendition(giph *Giph) Rendition {
+	if ho.Rendition != "" {
+		return ho.Rendition
+	}
+	for _, r := range hashRenditions {
+		if size := giph.Rendition(r); size != nil && size.URL != "" {
+			return r
+		}
+	}
+	return RenditionOriginal
+}
+
+// PerceptualHash hashes the first frame of giph, which is taken from
+// a still rendition when GIPHY has one, so that re-uploads of the
+// same clip under different IDs can be told apart from unrelated ones.
+func (c *Client) PerceptualHash(ctx context.Context, giph *Giph, opts *HashOptions) (PerceptualHash, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).PerceptualHash")
+	defer span.End()
+
+	if opts == nil {
+		opts = new(HashOptions)
+	}
+	frame, err := c.Frame(ctx, giph, &ThumbnailOptions{Rendition: opts.rendition(giph)})
+	if err != nil {
+		return 0, err
+	}
+	return Hash(frame, opts.Algorithm)
+}
+
+// HashIndex groups Giphs whose perceptual hashes are within
+// a threshold of one another. It is safe for concurrent use.
+type HashIndex struct {
+	threshold int
+
+	mu      sync.Mutex
+	entries []*hashEntry
+	groups  [][]*hashEntry
+}
+
+type hashEntry struct {
+	giph  *Giph
+	hash  PerceptualHash
+	group int
+	// seq is the order in which the entry was added.
+	seq int
+}
+
+// NewHashIndex returns an empty HashIndex that groups hashes at most
+// threshold bits apart, defaulting to DefaultHashThreshold if negative.
+func NewHashIndex(threshold int) *HashIndex {
+	if threshold < 0 {
+		threshold = DefaultHashThreshold
+	}
+	return &HashIndex{threshold: threshold}
+}
+
+// Add indexes giph under hash, returning the Giphs already indexed
+// that it is a near-duplicate of, closest first.
+func (hi *HashIndex) Add(giph *Giph, hash PerceptualHash) []*Giph {
+	hi.mu.Lock()
+	defer hi.mu.Unlock()
+
+	var matches []*hashEntry
+	for _, entry := range hi.entries {
+		if entry.hash.Distance(hash) <= hi.threshold {
+			matches = append(matches, entry)
+		}
+	}
+
+	added := &hashEntry{giph: giph, hash: hash, group: len(hi.groups), seq: len(hi.entries)}
+	if len(matches) == 0 {
+		hi.groups = append(hi.groups, []*hashEntry{added})
+	} else {
+		// Near-duplicates chain, so merge every group that
+		// giph bridges into the earliest of them.
+		added.group = matches[0].group
+		for _, match := range matches {
+			added.group = min(added.group, match.group)
+		}
+		for _, match := range matches {
+			hi.mergeGroups(added.group, match.group)
+		}
+		hi.groups[added.group] = append(hi.groups[added.group], added)
+	}
+	hi.entries = append(hi.entries, added)
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].hash.Distance(hash) < matches[j].hash.Distance(hash)
+	})
+	nearDuplicates := make([]*Giph, len(matches))
+	for i, match := range matches {
+		nearDuplicates[i] = match.giph
+	}
+	return nearDuplicates
+}
+
+func (hi *HashIndex) mergeGroups(into, from int) {
+	if into == from || hi.groups[from] == nil {
+		return
+	}
+	for _, entry := range hi.groups[from] {
+		entry.group = into
+	}
+	hi.groups[into] = append(hi.groups[into], hi.groups[from]...)
+	hi.groups[from] = nil
+}
+
+// Groups returns the indexed Giphs clustered by likeness, in the
+// order in which each group's first member was added.
+func (hi *HashIndex) Groups() [][]*Giph {
+	hi.mu.Lock()
+	defer hi.mu.Unlock()
+
+	var groups [][]*Giph
+	for _, group := range hi.groups {
+		if group == nil {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].seq < group[j].seq
+		})
+		giphs := make([]*Giph, len(group))
+		for i, entry := range group {
+			giphs[i] = entry.giph
+		}
+		groups = append(groups, giphs)
+	}
+	return groups
+}
+
+// GroupNearDuplicates hashes each of giphs and clusters the re-uploads
+// of the same clip together. Groups keep the order of giphs. The giphs
+// that fail to be hashed are left out of the groups and reported in the
+// error, which is returned along with the groups of the rest.
+func (c *Client) GroupNearDuplicates(ctx context.Context, giphs []*Giph, threshold int, opts *HashOptions) ([][]*Giph, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).GroupNearDuplicates")
+	defer span.End()
+
+	index := NewHashIndex(threshold)
+	var errs []error
+	for i, giph := range giphs {
+		if giph == nil {
+			errs = append(errs, fmt.Errorf("giph #%d: %w", i, errNilGiph))
+			continue
+		}
+		hash, err := c.PerceptualHash(ctx, giph, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("giph #%d %q: %w", i, giph.ID, err))
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				// None of the rest can be hashed either.
+				break
+			}
+			continue
+		}
+		index.Add(giph, hash)
+	}
+	return index.Groups(), errors.Join(errs...)
+}
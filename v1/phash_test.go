@@ -0,0 +1,293 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+// scene draws a left to right gradient, a top to bottom one and a
+// bright square near the top left corner, mirrored left to right if asked.
+func scene(width, height int, mirrored bool, lighten uint8) *image.Paletted {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(x * 200 / width)
+			if mirrored {
+				v = 200 - v
+			}
+			img.Set(x, y, color.RGBA{R: v + lighten, G: uint8(y*150/height) + lighten, B: 40 + lighten, A: 0xff})
+		}
+	}
+	square := image.Rect(width/8, height/8, width*3/8, height*3/8)
+	if mirrored {
+		square = image.Rect(width-square.Max.X, square.Min.Y, width-square.Min.X, square.Max.Y)
+	}
+	draw.Draw(img, square, image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+	draw.Draw(paletted, img.Bounds(), img, image.Point{}, draw.Src)
+	return paletted
+}
+
+func sceneGIF(frames ...*image.Paletted) *gif.GIF {
+	g := &gif.GIF{Config: image.Config{Width: frames[0].Rect.Dx(), Height: frames[0].Rect.Dy()}}
+	for _, frame := range frames {
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+	return g
+}
+
+func TestPerceptualHashDistance(t *testing.T) {
+	tests := [...]struct {
+		a, b giphy.PerceptualHash
+		want int
+	}{
+		0: {a: 0, b: 0, want: 0},
+		1: {a: 0xf0, b: 0x0f, want: 8},
+		2: {a: 0, b: ^giphy.PerceptualHash(0), want: 64},
+	}
+
+	for i, tt := range tests {
+		if got := tt.a.Distance(tt.b); got != tt.want {
+			t.Errorf("#%d: got %d want %d", i, got, tt.want)
+		}
+	}
+	if got, want := giphy.PerceptualHash(0xbeef).String(), "000000000000beef"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+func TestHash(t *testing.T) {
+	original := scene(64, 48, false, 0)
+	tests := [...]struct {
+		other   image.Image
+		similar bool
+	}{
+		0: {other: original, similar: true},
+		1: {other: scene(160, 120, false, 0), similar: true},
+		2: {other: scene(64, 48, false, 30), similar: true},
+		3: {other: scene(64, 48, true, 0), similar: false},
+	}
+
+	for _, algo := range []giphy.HashAlgorithm{giphy.HashDifference, giphy.HashDCT} {
+		want, err := giphy.Hash(original, algo)
+		if err != nil {
+			t.Fatalf("algo %d: err: %v", algo, err)
+		}
+		for i, tt := range tests {
+			got, err := giphy.Hash(tt.other, algo)
+			if err != nil {
+				t.Errorf("algo %d #%d: err: %v", algo, i, err)
+				continue
+			}
+			distance := got.Distance(want)
+			if similar := distance <= giphy.DefaultHashThreshold; similar != tt.similar {
+				t.Errorf("algo %d #%d: got distance %d, want similar=%t", algo, i, distance, tt.similar)
+			}
+		}
+	}
+
+	if _, err := giphy.Hash(original, giphy.HashAlgorithm(42)); err == nil {
+		t.Error("expected an error for an unknown algorithm")
+	}
+}
+
+func TestHashFrames(t *testing.T) {
+	plain, mirrored := scene(64, 48, false, 0), scene(64, 48, true, 0)
+	g := sceneGIF(plain, mirrored, plain, mirrored, plain)
+	plainHash, _ := giphy.Hash(plain, giphy.HashDifference)
+	mirroredHash, _ := giphy.Hash(mirrored, giphy.HashDifference)
+
+	tests := [...]struct {
+		samples int
+		want    []giphy.PerceptualHash
+	}{
+		0: {samples: 1, want: []giphy.PerceptualHash{plainHash}},
+		1: {samples: 2, want: []giphy.PerceptualHash{plainHash, plainHash}},
+		2: {samples: 3, want: []giphy.PerceptualHash{plainHash, mirroredHash, mirroredHash}},
+		3: {samples: 0, want: []giphy.PerceptualHash{plainHash, mirroredHash, plainHash, mirroredHash, plainHash}},
+		4: {samples: 9, want: []giphy.PerceptualHash{plainHash, mirroredHash, plainHash, mirroredHash, plainHash}},
+	}
+
+	for i, tt := range tests {
+		got, err := giphy.HashFrames(g, giphy.HashDifference, tt.samples)
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: got %v want %v", i, got, tt.want)
+		}
+	}
+
+	reversed, _ := giphy.HashFrames(sceneGIF(mirrored, plain, mirrored, plain, mirrored), giphy.HashDifference, 0)
+	same, _ := giphy.HashFrames(g, giphy.HashDifference, 0)
+	if d := giphy.SequenceDistance(same, same); d != 0 {
+		t.Errorf("got distance %f between identical sequences", d)
+	}
+	if d := giphy.SequenceDistance(same, reversed); d <= giphy.DefaultHashThreshold {
+		t.Errorf("got distance %f between different sequences", d)
+	}
+}
+
+func TestHashIndex(t *testing.T) {
+	giphs := make(map[string]*giphy.Giph)
+	giph := func(id string) *giphy.Giph {
+		if giphs[id] == nil {
+			giphs[id] = &giphy.Giph{ID: id}
+		}
+		return giphs[id]
+	}
+
+	type addition struct {
+		id      string
+		hash    giphy.PerceptualHash
+		matches []string
+	}
+	tests := [...]struct {
+		threshold  int
+		additions  []addition
+		wantGroups [][]string
+	}{
+		0: {
+			threshold: 2,
+			additions: []addition{
+				{id: "a", hash: 0x00},
+				{id: "far", hash: 0xffff},
+				{id: "b", hash: 0x03, matches: []string{"a"}},
+				{id: "c", hash: 0x01, matches: []string{"a", "b"}},
+			},
+			wantGroups: [][]string{{"a", "b", "c"}, {"far"}},
+		},
+		1: {
+			// "bridge" is near both ends, so their groups merge.
+			threshold: 2,
+			additions: []addition{
+				{id: "left", hash: 0x00},
+				{id: "right", hash: 0x0f},
+				{id: "bridge", hash: 0x03, matches: []string{"left", "right"}},
+			},
+			wantGroups: [][]string{{"left", "right", "bridge"}},
+		},
+		2: {
+			threshold: 0,
+			additions: []addition{
+				{id: "a", hash: 0x10},
+				{id: "b", hash: 0x11},
+				{id: "a-again", hash: 0x10, matches: []string{"a"}},
+			},
+			wantGroups: [][]string{{"a", "a-again"}, {"b"}},
+		},
+		3: {
+			// "e" bridges "c" into the group that "d" joined,
+			// which must keep the place of the group of "a".
+			threshold: 1,
+			additions: []addition{
+				{id: "a", hash: 0x00},
+				{id: "b", hash: 0xff00},
+				{id: "c", hash: 0x07},
+				{id: "d", hash: 0x01, matches: []string{"a"}},
+				{id: "e", hash: 0x03, matches: []string{"c", "d"}},
+			},
+			wantGroups: [][]string{{"a", "c", "d", "e"}, {"b"}},
+		},
+	}
+
+	ids := func(giphs []*giphy.Giph) []string {
+		var ids []string
+		for _, giph := range giphs {
+			ids = append(ids, giph.ID)
+		}
+		return ids
+	}
+	for i, tt := range tests {
+		index := giphy.NewHashIndex(tt.threshold)
+		for _, add := range tt.additions {
+			if got := ids(index.Add(giph(add.id), add.hash)); !reflect.DeepEqual(got, add.matches) {
+				t.Errorf("#%d: adding %q: got matches %q want %q", i, add.id, got, add.matches)
+			}
+		}
+		var gotGroups [][]string
+		for _, group := range index.Groups() {
+			gotGroups = append(gotGroups, ids(group))
+		}
+		if !reflect.DeepEqual(gotGroups, tt.wantGroups) {
+			t.Errorf("#%d: got groups %q want %q", i, gotGroups, tt.wantGroups)
+		}
+	}
+}
+
+func TestGroupNearDuplicates(t *testing.T) {
+	server := newPathServer(map[string][]byte{
+		"/cat.gif":          encodeGIF(t, sceneGIF(scene(64, 48, false, 0), scene(64, 48, true, 0))),
+		"/cat-reupload.gif": encodeGIF(t, sceneGIF(scene(120, 90, false, 0))),
+		"/dog-still.gif":    encodeGIF(t, sceneGIF(scene(64, 48, true, 0))),
+	})
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cat := &giphy.Giph{ID: "cat", Sizes: map[string]*giphy.GIF{
+		"original": {URL: server.URL + "/cat.gif"},
+	}}
+	reupload := &giphy.Giph{ID: "cat-reupload", Sizes: map[string]*giphy.GIF{
+		"original":          {URL: server.URL + "/huge.gif"},
+		"fixed_width_small": {URL: server.URL + "/cat-reupload.gif"},
+	}}
+	dog := &giphy.Giph{ID: "dog", Sizes: map[string]*giphy.GIF{
+		"fixed_width":       {URL: server.URL + "/dog.gif"},
+		"fixed_width_still": {URL: server.URL + "/dog-still.gif"},
+	}}
+
+	for _, algo := range []giphy.HashAlgorithm{giphy.HashDifference, giphy.HashDCT} {
+		groups, err := client.GroupNearDuplicates(context.Background(), []*giphy.Giph{cat, dog, reupload}, -1, &giphy.HashOptions{Algorithm: algo})
+		if err != nil {
+			t.Fatalf("algo %d: err: %v", algo, err)
+		}
+		want := [][]*giphy.Giph{{cat, reupload}, {dog}}
+		if !reflect.DeepEqual(groups, want) {
+			t.Errorf("algo %d: got %d groups %v want %v", algo, len(groups), groups, want)
+		}
+		wantRequested := []string{"/cat.gif", "/dog-still.gif", "/cat-reupload.gif"}
+		if got := server.takeRequested(); !reflect.DeepEqual(got, wantRequested) {
+			t.Errorf("algo %d: got requests %q want %q", algo, got, wantRequested)
+		}
+	}
+
+	// The rendition of "missing" is a 404, which must
+	// not cost the groups of the giphs that were hashed.
+	missing := &giphy.Giph{ID: "missing", Sizes: map[string]*giphy.GIF{"original": {URL: server.URL + "/missing.gif"}}}
+	groups, err := client.GroupNearDuplicates(context.Background(), []*giphy.Giph{cat, missing, dog, reupload}, -1, nil)
+	if err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("expected an error for the unavailable giph, got: %v", err)
+	}
+	if want := [][]*giphy.Giph{{cat, reupload}, {dog}}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("got %d groups %v want %v", len(groups), groups, want)
+	}
+}
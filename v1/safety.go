@@ -0,0 +1,187 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SafetyPolicy restricts the content that a Client returns, regardless
+// of what each Request asks for. Ratings are capped in outgoing requests
+// and every returned Giph is checked again client-side.
+type SafetyPolicy struct {
+	// MaxRating is the most mature rating allowed. Giphs with a
+	// higher, unknown or missing rating are filtered out.
+	MaxRating Rating `json:"max_rating"`
+
+	BlockedIDs []string `json:"blocked_ids"`
+
+	// BlockedKeywords are matched case-insensitively as whole words
+	// against the title, slug, alt text and uploader of each Giph.
+	BlockedKeywords []string `json:"blocked_keywords"`
+}
+
+// FilterStats counts the Giphs that were dropped from a Page.
+type FilterStats struct {
+	// Rating, ID and Keyword are the drops by the SafetyPolicy.
+	Rating  int `json:"rating,omitempty"`
+	ID      int `json:"id,omitempty"`
+	Keyword int `json:"keyword,omitempty"`
+
+	// Filters are the drops by Request.Filters.
+	Filters int `json:"filters,omitempty"`
+
+	// Duplicates are the drops by Request.Dedupe.
+	Duplicates int `json:"duplicates,omitempty"`
+}
+
+func (fs *FilterStats) Total() int {
+	if fs == nil {
+		return 0
+	}
+	return fs.Rating + fs.ID + fs.Keyword + fs.Filters + fs.Duplicates
+}
+
+var ErrBlockedBySafetyPolicy = errors.New("giph blocked by the safety policy")
+
+// ratingRanks orders ratings from the least to the most mature.
+var ratingRanks = map[Rating]int{
+	RatingYouth:   0,
+	RatingGeneral: 1,
+	RatingPG:      2,
+	RatingPG13:    3,
+	RatingR:       4,
+}
+
+// safetyPolicy is the compiled form of a SafetyPolicy.
+type safetyPolicy struct {
+	maxRating  Rating
+	blockedIDs map[string]bool
+	keywords   []string
+}
+
+// SetSafetyPolicy applies sp to all subsequent requests made by c.
+// A nil SafetyPolicy removes any restrictions.
+func (c *Client) SetSafetyPolicy(sp *SafetyPolicy) error {
+	var compiled *safetyPolicy
+	if sp != nil {
+		if sp.MaxRating != "" && !sp.MaxRating.isKnown() {
+			return &FieldError{Field: "MaxRating", Reason: fmt.Sprintf("unknown rating %q", sp.MaxRating)}
+		}
+		compiled = &safetyPolicy{maxRating: sp.MaxRating, blockedIDs: make(map[string]bool)}
+		for _, id := range sp.BlockedIDs {
+			compiled.blockedIDs[id] = true
+		}
+		for _, keyword := range sp.BlockedKeywords {
+			if normalized := normalizeWords(keyword); normalized != " " {
+				compiled.keywords = append(compiled.keywords, normalized)
+			}
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.safety = compiled
+	return nil
+}
+
+func (c *Client) safetyPolicy() *safetyPolicy {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.safety
+}
+
+// withSafety returns req with its rating capped by the safety policy.
+// req itself is left untouched since callers may reuse it.
+func (c *Client) withSafety(req *Request) *Request {
+	sp := c.safetyPolicy()
+	if sp == nil || sp.maxRating == "" {
+		return req
+	}
+	rank, known := ratingRanks[req.Rating]
+	if known && rank <= ratingRanks[sp.maxRating] {
+		return req
+	}
+	capped := *req
+	capped.Rating = sp.maxRating
+	return &capped
+}
+
+// filter drops the giphs that sp disallows, tallying why into stats.
+func (sp *safetyPolicy) filter(giphs []*Giph, stats *FilterStats) []*Giph {
+	if sp == nil {
+		return giphs
+	}
+	kept := giphs[:0]
+	for _, giph := range giphs {
+		switch {
+		case !sp.allowsRating(giph):
+			stats.Rating++
+		case sp.blockedIDs[giph.ID]:
+			stats.ID++
+		case sp.matchesKeyword(giph):
+			stats.Keyword++
+		default:
+			kept = append(kept, giph)
+		}
+	}
+	return kept
+}
+
+func (sp *safetyPolicy) allows(giph *Giph) bool {
+	return len(sp.filter([]*Giph{giph}, new(FilterStats))) == 1
+}
+
+func (sp *safetyPolicy) allowsRating(giph *Giph) bool {
+	return sp.maxRating == "" || withinRating(giph.Rating, sp.maxRating)
+}
+
+// withinRating reports whether rating is known and no more mature than max.
+func withinRating(rating string, max Rating) bool {
+	rank, known := ratingRanks[Rating(strings.ToLower(rating))]
+	return known && rank <= ratingRanks[max]
+}
+
+func (sp *safetyPolicy) matchesKeyword(giph *Giph) bool {
+	if len(sp.keywords) == 0 {
+		return false
+	}
+	texts := []string{giph.Title, giph.Slug, giph.RawAltText, giph.Owner}
+	if giph.User != nil {
+		texts = append(texts, giph.User.Username, giph.User.DisplayName)
+	}
+	haystack := normalizeWords(strings.Join(texts, " "))
+	for _, keyword := range sp.keywords {
+		if strings.Contains(haystack, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWords lowercases text and separates its words with single
+// spaces, padding both ends so that whole words can be matched with
+// strings.Contains e.g. "Happy-Cat_Dance" becomes " happy cat dance ".
+func normalizeWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return " " + strings.Join(words, " ") + " "
+}
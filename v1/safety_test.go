@@ -0,0 +1,179 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+// ratingRecorder serves a single page of fixture
+// and records the ratings that were requested.
+type ratingRecorder struct {
+	fixture string
+
+	mu      sync.Mutex
+	ratings []string
+}
+
+func (rr *ratingRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	rr.mu.Lock()
+	rr.ratings = append(rr.ratings, req.URL.Query().Get("rating"))
+	rr.mu.Unlock()
+
+	if req.URL.Query().Get("offset") != "" {
+		return blankDataResp(), nil
+	}
+	f, err := os.Open(rr.fixture)
+	if err != nil {
+		return nil, err
+	}
+	return makeResp("200", http.StatusOK, f), nil
+}
+
+func TestSafetyPolicyFiltersPages(t *testing.T) {
+	policy := &giphy.SafetyPolicy{
+		MaxRating:  giphy.RatingPG,
+		BlockedIDs: []string{"3KVxWo2cS6tP2"},
+		// "clark" must not match the "jasonclarke" uploads.
+		BlockedKeywords: []string{"Rainbow", "party friday", "clark"},
+	}
+
+	tests := [...]struct {
+		req          *giphy.Request
+		policy       *giphy.SafetyPolicy
+		wantRating   string
+		wantGiphs    int
+		wantFiltered *giphy.FilterStats
+	}{
+		0: {
+			req:          &giphy.Request{Rating: giphy.RatingR},
+			policy:       policy,
+			wantRating:   "pg",
+			wantGiphs:    19,
+			wantFiltered: &giphy.FilterStats{Rating: 3, ID: 1, Keyword: 2},
+		},
+		1: {
+			req:          &giphy.Request{},
+			policy:       policy,
+			wantRating:   "pg",
+			wantGiphs:    19,
+			wantFiltered: &giphy.FilterStats{Rating: 3, ID: 1, Keyword: 2},
+		},
+		2: {
+			// A stricter request rating is kept as is.
+			req:          &giphy.Request{Rating: giphy.RatingGeneral},
+			policy:       &giphy.SafetyPolicy{MaxRating: giphy.RatingPG13},
+			wantRating:   "g",
+			wantGiphs:    23,
+			wantFiltered: &giphy.FilterStats{Rating: 2},
+		},
+		3: {
+			req:        &giphy.Request{Rating: giphy.RatingR},
+			policy:     nil,
+			wantRating: "r",
+			wantGiphs:  25,
+		},
+	}
+
+	for i, tt := range tests {
+		client, err := giphy.NewClient(testAPIKey1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorder := &ratingRecorder{fixture: "./testdata/trending-stickers-0.json"}
+		client.SetHTTPRoundTripper(recorder)
+		if err := client.SetSafetyPolicy(tt.policy); err != nil {
+			t.Fatalf("#%d: SetSafetyPolicy: %v", i, err)
+		}
+
+		origRating := tt.req.Rating
+		res, err := client.TrendingStickers(context.Background(), tt.req)
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		var pages []*giphy.Page
+		for page := range res.Pages {
+			pages = append(pages, page)
+		}
+		if len(pages) != 1 || pages[0].Err != nil {
+			t.Errorf("#%d: got pages=%+v want a single page", i, pages)
+			continue
+		}
+		page := pages[0]
+		if got := len(page.Giphs); got != tt.wantGiphs {
+			t.Errorf("#%d: got %d giphs want %d", i, got, tt.wantGiphs)
+		}
+		if got, want := page.Filtered.Total(), tt.wantFiltered.Total(); got != want {
+			t.Errorf("#%d: got %d filtered want %d", i, got, want)
+		}
+		if tt.wantFiltered != nil && *page.Filtered != *tt.wantFiltered {
+			t.Errorf("#%d: gotFiltered: %+v wantFiltered: %+v", i, page.Filtered, tt.wantFiltered)
+		}
+		for _, rating := range recorder.ratings {
+			if rating != tt.wantRating {
+				t.Errorf("#%d: requested rating %q want %q", i, rating, tt.wantRating)
+			}
+		}
+		// The caller's request must not be modified.
+		if tt.req.Rating != origRating {
+			t.Errorf("#%d: the request's rating was overwritten with %q", i, tt.req.Rating)
+		}
+	}
+}
+
+func TestSafetyPolicyBlocksSingleGiphs(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: gifByIDRoute})
+
+	const id = "3ohze2UfcItWPUFqbm"
+	tests := [...]struct {
+		policy  *giphy.SafetyPolicy
+		wantErr error
+	}{
+		0: {policy: nil},
+		1: {policy: &giphy.SafetyPolicy{MaxRating: giphy.RatingPG}},
+		2: {policy: &giphy.SafetyPolicy{MaxRating: giphy.RatingGeneral}, wantErr: giphy.ErrBlockedBySafetyPolicy},
+		3: {policy: &giphy.SafetyPolicy{BlockedIDs: []string{id}}, wantErr: giphy.ErrBlockedBySafetyPolicy},
+	}
+
+	for i, tt := range tests {
+		if err := client.SetSafetyPolicy(tt.policy); err != nil {
+			t.Fatalf("#%d: SetSafetyPolicy: %v", i, err)
+		}
+		giph, err := client.GIFByID(context.Background(), id)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("#%d: gotErr: %v wantErr: %v", i, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr == nil && (giph == nil || giph.ID != id) {
+			t.Errorf("#%d: got giph %+v want %q", i, giph, id)
+		}
+	}
+
+	if err := client.SetSafetyPolicy(&giphy.SafetyPolicy{MaxRating: "nc-17"}); err == nil {
+		t.Error("expected an error for an unknown MaxRating")
+	}
+}
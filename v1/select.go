@@ -0,0 +1,300 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+type SelectOptions struct {
+	// Width and Height are the size, in pixels, that the media will
+	// be displayed at. Either or both may be zero if unconstrained.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// MaxBytes, if positive, rules out renditions known to be larger.
+	MaxBytes int64 `json:"max_bytes"`
+
+	// Formats lists the acceptable formats, most preferred first.
+	// It defaults to MediaWebP, MediaMP4 then MediaGIF.
+	Formats []MediaFormat `json:"formats"`
+
+	// Still selects still images instead of animations.
+	Still bool `json:"still"`
+
+	// AspectTolerance, if positive, rules out renditions whose aspect
+	// ratio differs by more than that fraction from Width/Height, or from
+	// the original rendition's if both are not set. 0.05 allows 5%.
+	AspectTolerance float64 `json:"aspect_tolerance"`
+}
+
+var defaultFormats = []MediaFormat{MediaWebP, MediaMP4, MediaGIF}
+
+// Selection is a candidate rendition in one of its formats.
+type Selection struct {
+	Rendition Rendition   `json:"rendition"`
+	Format    MediaFormat `json:"format"`
+	GIF       *GIF        `json:"gif"`
+
+	URL string `json:"url"`
+	// Size is the number of bytes, or 0 if GIPHY did not report it.
+	Size int64 `json:"size,omitempty"`
+
+	// Score ranks the Selection against the other candidates,
+	// from 0 up to 100 for a perfect fit.
+	Score float64 `json:"score"`
+	// Reasons explain how Score was arrived at.
+	Reasons []string `json:"reasons"`
+}
+
+var errNoRenditionFits = errors.New("no rendition satisfies the constraints")
+
+// SelectRendition returns the rendition of g that best fits opts.
+func (g *Giph) SelectRendition(opts *SelectOptions) (*Selection, error) {
+	candidates := g.Candidates(opts)
+	if len(candidates) == 0 {
+		return nil, errNoRenditionFits
+	}
+	return candidates[0], nil
+}
+
+// Candidates returns every rendition and format of g that satisfies
+// the constraints of opts, from the highest score to the lowest.
+func (g *Giph) Candidates(opts *SelectOptions) []*Selection {
+	if g == nil {
+		return nil
+	}
+	if opts == nil {
+		opts = new(SelectOptions)
+	}
+	formats := opts.Formats
+	if len(formats) == 0 {
+		formats = defaultFormats
+	}
+
+	aspect := 0.0
+	if opts.Width > 0 && opts.Height > 0 {
+		aspect = float64(opts.Width) / float64(opts.Height)
+	} else if original := g.Rendition(RenditionOriginal); original != nil && original.Height > 0 {
+		aspect = float64(original.Width) / float64(original.Height)
+	}
+
+	var all []*Selection
+	for _, name := range sortedRenditions(g.Sizes) {
+		gif := g.Sizes[string(name)]
+		if gif == nil || name.IsStill() != opts.Still {
+			continue
+		}
+		for _, sel := range gif.selections(name) {
+			if sel.Format == MediaMP4 && opts.Still {
+				continue
+			}
+			rank := formatRank(formats, sel.Format)
+			if rank < 0 {
+				continue
+			}
+			if opts.MaxBytes > 0 && sel.Size > opts.MaxBytes {
+				continue
+			}
+			if opts.AspectTolerance > 0 && aspect > 0 {
+				if gif.Width <= 0 || gif.Height <= 0 {
+					continue
+				}
+				deviation := math.Abs(float64(gif.Width)/float64(gif.Height)/aspect - 1)
+				if deviation > opts.AspectTolerance {
+					continue
+				}
+			}
+			sel.score(opts, rank, len(formats))
+			all = append(all, sel)
+		}
+	}
+
+	if opts.Width <= 0 && opts.Height <= 0 {
+		scoreByArea(all)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Score > all[j].Score })
+	return all
+}
+
+// IsStill reports whether r is a still image rather than an animation.
+func (r Rendition) IsStill() bool {
+	return strings.HasSuffix(string(r), "_still")
+}
+
+// sortedRenditions orders the known renditions first, as listed
+// in KnownRenditions, followed by any others alphabetically.
+func sortedRenditions(sizes map[string]*GIF) []Rendition {
+	var names []Rendition
+	for _, r := range KnownRenditions {
+		if _, ok := sizes[string(r)]; ok {
+			names = append(names, r)
+		}
+	}
+	var unknown []string
+	for name := range sizes {
+		if !Rendition(name).IsKnown() {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		names = append(names, Rendition(name))
+	}
+	return names
+}
+
+func formatRank(formats []MediaFormat, format MediaFormat) int {
+	for i, f := range formats {
+		if f == format {
+			return i
+		}
+	}
+	return -1
+}
+
+// selections lists the formats that gif is available in. The format of
+// its URL is told by its extension since, for instance, "preview_webp"
+// carries a WebP in URL.
+func (gif *GIF) selections(name Rendition) []*Selection {
+	var sels []*Selection
+	add := func(theURL string, format MediaFormat, size int64) {
+		if theURL != "" {
+			sels = append(sels, &Selection{Rendition: name, Format: format, GIF: gif, URL: theURL, Size: size})
+		}
+	}
+	add(gif.URL, formatOfURL(gif.URL), gif.Size)
+	add(gif.MP4, MediaMP4, gif.MP4Size)
+	add(gif.Webp, MediaWebP, gif.WebpSize)
+	return sels
+}
+
+func formatOfURL(theURL string) MediaFormat {
+	if u, err := url.Parse(theURL); err == nil {
+		switch strings.ToLower(path.Ext(u.Path)) {
+		case ".webp":
+			return MediaWebP
+		case ".mp4":
+			return MediaMP4
+		}
+	}
+	return MediaGIF
+}
+
+// score weighs how closely sel fits the target size, by how
+// preferred its format is and by whether its size is known.
+func (sel *Selection) score(opts *SelectOptions, rank, nFormats int) {
+	fit := 1.0
+	if opts.Width > 0 || opts.Height > 0 {
+		// The scale that sel must be displayed at, the smaller
+		// of the two if both the width and height are targeted.
+		scale := math.Inf(1)
+		if opts.Width > 0 && sel.GIF.Width > 0 {
+			scale = float64(sel.GIF.Width) / float64(opts.Width)
+		}
+		if opts.Height > 0 && sel.GIF.Height > 0 {
+			scale = math.Min(scale, float64(sel.GIF.Height)/float64(opts.Height))
+		}
+		switch {
+		case math.IsInf(scale, 1):
+			fit = 0.25
+			sel.Reasons = append(sel.Reasons, "dimensions unknown")
+		case scale == 1:
+			sel.Reasons = append(sel.Reasons, fmt.Sprintf("%dx%d matches the target", sel.GIF.Width, sel.GIF.Height))
+		case scale > 1:
+			// Prefer the smallest rendition that covers the target.
+			fit = 1 / scale
+			sel.Reasons = append(sel.Reasons, fmt.Sprintf("%dx%d is %.2fx the target, downscaled", sel.GIF.Width, sel.GIF.Height, scale))
+		default:
+			// Upscaling blurs so it costs twice as much.
+			fit = scale / 2
+			sel.Reasons = append(sel.Reasons, fmt.Sprintf("%dx%d is %.2fx the target, upscaled", sel.GIF.Width, sel.GIF.Height, scale))
+		}
+	}
+
+	// Formats are weighed from 1 for the most preferred down to 0.5.
+	formatWeight := 1 - 0.5*float64(rank)/float64(nFormats)
+	sel.Reasons = append(sel.Reasons, fmt.Sprintf("%s is preferred format #%d", sel.Format, rank+1))
+
+	sizeWeight := 1.0
+	switch {
+	case sel.Size <= 0:
+		sizeWeight = 0.9
+		sel.Reasons = append(sel.Reasons, "byte size unknown")
+	case opts.MaxBytes > 0:
+		sel.Reasons = append(sel.Reasons, fmt.Sprintf("%d bytes within the %d byte budget", sel.Size, opts.MaxBytes))
+	}
+
+	sel.Score = 100 * fit * formatWeight * sizeWeight
+}
+
+// scoreByArea favors larger renditions when no target size
+// is set, scaling scores by each one's share of the largest area.
+func scoreByArea(sels []*Selection) {
+	maxArea := 0
+	for _, sel := range sels {
+		if area := sel.GIF.Width * sel.GIF.Height; area > maxArea {
+			maxArea = area
+		}
+	}
+	if maxArea == 0 {
+		return
+	}
+	for _, sel := range sels {
+		area := sel.GIF.Width * sel.GIF.Height
+		sel.Score *= float64(area) / float64(maxArea)
+		sel.Reasons = append(sel.Reasons, fmt.Sprintf("%dx%d covers %.0f%% of the largest area", sel.GIF.Width, sel.GIF.Height, 100*float64(area)/float64(maxArea)))
+	}
+}
+
+// Srcset builds the value of an HTML srcset attribute, such as
+// "https://…/200w.webp 200w, https://…/giphy.webp 480w", out of
+// candidates in the format of the first one. MP4s cannot be listed in
+// an img srcset so they are skipped. For every width, the candidate that
+// comes first in candidates is used.
+func Srcset(candidates []*Selection) string {
+	var format MediaFormat
+	byWidth := make(map[int]string)
+	var widths []int
+	for _, sel := range candidates {
+		if sel.Format == MediaMP4 || sel.GIF.Width <= 0 {
+			continue
+		}
+		if format == "" {
+			format = sel.Format
+		}
+		if sel.Format != format {
+			continue
+		}
+		if _, seen := byWidth[sel.GIF.Width]; seen {
+			continue
+		}
+		byWidth[sel.GIF.Width] = sel.URL
+		widths = append(widths, sel.GIF.Width)
+	}
+	sort.Ints(widths)
+
+	entries := make([]string, 0, len(widths))
+	for _, width := range widths {
+		entries = append(entries, fmt.Sprintf("%s %dw", byWidth[width], width))
+	}
+	return strings.Join(entries, ", ")
+}
@@ -0,0 +1,132 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestSelectRendition(t *testing.T) {
+	giph := loadFixtureGiphs(t, "search-0.json")[0]
+
+	tests := [...]struct {
+		opts          *giphy.SelectOptions
+		wantRendition giphy.Rendition
+		wantFormat    giphy.MediaFormat
+		wantErr       bool
+	}{
+		0: {opts: nil, wantRendition: giphy.RenditionOriginal, wantFormat: giphy.MediaWebP},
+		1: {opts: &giphy.SelectOptions{Width: 200}, wantRendition: giphy.RenditionFixedWidth, wantFormat: giphy.MediaWebP},
+		2: {
+			opts:          &giphy.SelectOptions{Width: 200, Formats: []giphy.MediaFormat{giphy.MediaMP4, giphy.MediaGIF}},
+			wantRendition: giphy.RenditionFixedWidth, wantFormat: giphy.MediaMP4,
+		},
+		3: {
+			// Both dimensions: the smallest rendition that covers the box.
+			opts:          &giphy.SelectOptions{Width: 230, Height: 190, Formats: []giphy.MediaFormat{giphy.MediaGIF}},
+			wantRendition: giphy.RenditionFixedHeight, wantFormat: giphy.MediaGIF,
+		},
+		4: {
+			opts:          &giphy.SelectOptions{Width: 200, MaxBytes: 50000},
+			wantRendition: giphy.RenditionFixedWidthDownsampled, wantFormat: giphy.MediaWebP,
+		},
+		5: {
+			opts:          &giphy.SelectOptions{Width: 120, Still: true},
+			wantRendition: giphy.RenditionFixedHeightSmallStill, wantFormat: giphy.MediaGIF,
+		},
+		6: {
+			// preview_webp carries a WebP in its url field.
+			opts:          &giphy.SelectOptions{Width: 190, MaxBytes: 47000, Formats: []giphy.MediaFormat{giphy.MediaWebP}},
+			wantRendition: giphy.RenditionPreviewWebp, wantFormat: giphy.MediaWebP,
+		},
+		7: {opts: &giphy.SelectOptions{Width: 300, Height: 300, AspectTolerance: 0.05}, wantErr: true},
+		8: {opts: &giphy.SelectOptions{MaxBytes: 100}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		sel, err := giph.SelectRendition(tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error, got %+v", i, sel)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if sel.Rendition != tt.wantRendition || sel.Format != tt.wantFormat {
+			t.Errorf("#%d: got %s/%s want %s/%s, reasons: %q", i, sel.Rendition, sel.Format, tt.wantRendition, tt.wantFormat, sel.Reasons)
+		}
+		if sel.URL == "" || sel.Score <= 0 || sel.Score > 100 || len(sel.Reasons) == 0 {
+			t.Errorf("#%d: incomplete selection %+v", i, sel)
+		}
+	}
+}
+
+func TestCandidatesAreRanked(t *testing.T) {
+	giph := loadFixtureGiphs(t, "search-0.json")[0]
+	candidates := giph.Candidates(&giphy.SelectOptions{Width: 200})
+	if len(candidates) == 0 {
+		t.Fatal("expected candidates")
+	}
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].Score > candidates[i-1].Score {
+			t.Errorf("#%d: %.2f ranked below %.2f", i, candidates[i].Score, candidates[i-1].Score)
+		}
+	}
+	for _, sel := range candidates {
+		if sel.Rendition.IsStill() {
+			t.Errorf("%s: stills must not be candidates for animations", sel.Rendition)
+		}
+	}
+}
+
+func TestSrcset(t *testing.T) {
+	giph := loadFixtureGiphs(t, "search-0.json")[0]
+	candidates := giph.Candidates(&giphy.SelectOptions{Width: 200, Formats: []giphy.MediaFormat{giphy.MediaMP4, giphy.MediaGIF}})
+	srcset := giphy.Srcset(candidates)
+
+	entries := strings.Split(srcset, ", ")
+	if len(entries) < 2 {
+		t.Fatalf("got srcset %q want several entries", srcset)
+	}
+	lastWidth := 0
+	for _, entry := range entries {
+		fields := strings.Fields(entry)
+		if len(fields) != 2 || !strings.HasSuffix(fields[1], "w") {
+			t.Errorf("malformed entry %q", entry)
+			continue
+		}
+		if strings.Contains(fields[0], ".mp4") || strings.Contains(fields[0], ".webp") {
+			t.Errorf("%q: only GIFs were expected", fields[0])
+		}
+		width, err := strconv.Atoi(strings.TrimSuffix(fields[1], "w"))
+		if err != nil || width <= lastWidth {
+			t.Errorf("%q: widths must be unique and ascending", entry)
+		}
+		lastWidth = width
+	}
+	if !strings.Contains(srcset, "/200w.gif") {
+		t.Errorf("srcset %q is missing the fixed_width rendition", srcset)
+	}
+	if got := giphy.Srcset(nil); got != "" {
+		t.Errorf("got %q want a blank srcset", got)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opencensus.io/trace"
+)
+
+type randomIDWrap struct {
+	Data struct {
+		RandomID string `json:"random_id"`
+	} `json:"data"`
+}
+
+var errBlankRandomID = errors.New("expecting a non-blank random ID")
+
+// NewRandomID asks GIPHY for a unique ID that identifies an end user
+// so that search and trending results can be personalized for them.
+func (c *Client) NewRandomID(ctx context.Context) (string, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).NewRandomID")
+	defer span.End()
+
+	qv := make(url.Values)
+	qv.Set("api_key", c._apiKey())
+	theURL := fmt.Sprintf("%s/randomid?%s", baseURL, qv.Encode())
+	httpReq, err := http.NewRequest("GET", theURL, nil)
+	if err != nil {
+		return "", err
+	}
+	slurp, _, err := c.doHTTPReq(ctx, httpReq)
+	if err != nil {
+		return "", err
+	}
+	rWrap := new(randomIDWrap)
+	if err := json.Unmarshal(slurp, rWrap); err != nil {
+		return "", err
+	}
+	if rWrap.Data.RandomID == "" {
+		return "", errEmptyResponse
+	}
+	return rWrap.Data.RandomID, nil
+}
+
+// Session makes requests on behalf of a single end user,
+// attaching that user's random ID to every request.
+type Session struct {
+	client   *Client
+	randomID string
+
+	// seen is shared by the requests that deduplicate
+	// without a SeenSet of their own.
+	seen SeenSet
+}
+
+// NewSession creates a Session with a freshly minted random ID.
+func (c *Client) NewSession(ctx context.Context) (*Session, error) {
+	randomID, err := c.NewRandomID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{client: c, randomID: randomID, seen: NewSeenSet()}, nil
+}
+
+// ResumeSession creates a Session for a random ID that
+// was previously retrieved from NewRandomID and persisted.
+func (c *Client) ResumeSession(randomID string) (*Session, error) {
+	randomID = strings.TrimSpace(randomID)
+	if randomID == "" {
+		return nil, errBlankRandomID
+	}
+	return &Session{client: c, randomID: randomID, seen: NewSeenSet()}, nil
+}
+
+func (s *Session) RandomID() string {
+	return s.randomID
+}
+
+func (s *Session) personalize(req *Request) *Request {
+	sreq := new(Request)
+	if req != nil {
+		*sreq = *req
+	}
+	sreq.RandomID = s.randomID
+	if sreq.Dedupe != nil && sreq.Dedupe.Seen == nil {
+		dedupe := *sreq.Dedupe
+		dedupe.Seen = s.seen
+		sreq.Dedupe = &dedupe
+	}
+	return sreq
+}
+
+func (s *Session) Search(ctx context.Context, req *Request) (*ResponsePager, error) {
+	return s.client.Search(ctx, s.personalize(req))
+}
+
+func (s *Session) SearchStickers(ctx context.Context, req *Request) (*ResponsePager, error) {
+	return s.client.SearchStickers(ctx, s.personalize(req))
+}
+
+func (s *Session) Trending(ctx context.Context, req *Request) (*ResponsePager, error) {
+	return s.client.Trending(ctx, s.personalize(req))
+}
+
+func (s *Session) TrendingStickers(ctx context.Context, req *Request) (*ResponsePager, error) {
+	return s.client.TrendingStickers(ctx, s.personalize(req))
+}
+
+func (s *Session) RandomGIF(ctx context.Context, req *Request) (*Giph, error) {
+	return s.client.RandomGIF(ctx, s.personalize(req))
+}
+
+func (s *Session) RandomSticker(ctx context.Context, req *Request) (*Giph, error) {
+	return s.client.RandomSticker(ctx, s.personalize(req))
+}
+
+func (s *Session) Translate(ctx context.Context, req *Request) (*Giph, error) {
+	return s.client.Translate(ctx, s.personalize(req))
+}
@@ -0,0 +1,233 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"math"
+	"strconv"
+
+	"go.opencensus.io/trace"
+)
+
+type SheetOptions struct {
+	// Rendition is the one laid out, defaulting to RenditionOriginal.
+	// PageSheet takes the first frames of each Giph's rendition.
+	Rendition Rendition `json:"rendition,omitempty"`
+
+	// Columns defaults to as many as make the sheet roughly square.
+	Columns int `json:"columns,omitempty"`
+
+	// CellWidth and CellHeight bound each image on the sheet, which
+	// keeps its aspect ratio. Zero leaves that dimension unconstrained
+	// for contact sheets, while PageSheet defaults them both to
+	// DefaultCellSize since the Giphs of a Page differ in size.
+	CellWidth  int `json:"cell_width,omitempty"`
+	CellHeight int `json:"cell_height,omitempty"`
+
+	// Padding is the space around the cells, in pixels.
+	Padding int `json:"padding,omitempty"`
+
+	// Labels draws the index of each cell in its bottom left corner.
+	Labels bool `json:"labels,omitempty"`
+
+	// Background defaults to white.
+	Background color.Color `json:"-"`
+}
+
+// DefaultCellSize is the size of the cells of a PageSheet
+// when SheetOptions sets neither CellWidth nor CellHeight.
+const DefaultCellSize = 150
+
+var errNoSheetImages = errors.New("expecting at least one image for the sheet")
+
+// ContactSheet lays out every frame of g, as it is displayed, in a grid.
+func ContactSheet(g *gif.GIF, opts *SheetOptions) (*image.RGBA, error) {
+	if g == nil || len(g.Image) == 0 {
+		return nil, errNoFrames
+	}
+	if opts == nil {
+		opts = new(SheetOptions)
+	}
+	// Frames are scaled and laid out as they are composited,
+	// so that only the sheet is kept in memory.
+	var grid *sheetGrid
+	compositeFrames(g, func(i int, canvas *image.RGBA) bool {
+		var frame image.Image = canvas
+		bounds := canvas.Bounds()
+		width, height := fitWithin(bounds.Dx(), bounds.Dy(), opts.CellWidth, opts.CellHeight)
+		if width != bounds.Dx() || height != bounds.Dy() {
+			frame = Resize(canvas, width, height)
+		}
+		if grid == nil {
+			grid = newSheetGrid(len(g.Image), image.Pt(width, height), opts)
+		}
+		grid.place(i, frame)
+		return true
+	})
+	return grid.sheet, nil
+}
+
+// layoutSheet scales each image to fit within maxWidth by maxHeight,
+// centering it in a cell of the largest scaled size, and arranges the
+// cells in rows. Nil images leave their cells blank.
+func layoutSheet(images []image.Image, maxWidth, maxHeight int, opts *SheetOptions) (*image.RGBA, error) {
+	if len(images) == 0 {
+		return nil, errNoSheetImages
+	}
+
+	scaled := make([]image.Image, len(images))
+	var cell image.Point
+	for i, img := range images {
+		if img == nil {
+			continue
+		}
+		bounds := img.Bounds()
+		width, height := fitWithin(bounds.Dx(), bounds.Dy(), maxWidth, maxHeight)
+		if width != bounds.Dx() || height != bounds.Dy() {
+			img = Resize(img, width, height)
+		}
+		scaled[i] = img
+		cell.X, cell.Y = max(cell.X, width), max(cell.Y, height)
+	}
+	if cell.X == 0 || cell.Y == 0 {
+		cell = image.Pt(max(maxWidth, 1), max(maxHeight, 1))
+	}
+
+	grid := newSheetGrid(len(images), cell, opts)
+	for i, img := range scaled {
+		grid.place(i, img)
+	}
+	return grid.sheet, nil
+}
+
+// sheetGrid is a sheet of n cells of the same size, arranged in rows.
+type sheetGrid struct {
+	sheet   *image.RGBA
+	cell    image.Point
+	columns int
+	padding int
+	labels  bool
+}
+
+func newSheetGrid(n int, cell image.Point, opts *SheetOptions) *sheetGrid {
+	columns := opts.Columns
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(n))))
+	}
+	columns = min(columns, n)
+	rows := (n + columns - 1) / columns
+	padding := max(opts.Padding, 0)
+
+	sheet := image.NewRGBA(image.Rect(0, 0,
+		columns*(cell.X+padding)+padding,
+		rows*(cell.Y+padding)+padding))
+	background := opts.Background
+	if background == nil {
+		background = color.White
+	}
+	draw.Draw(sheet, sheet.Rect, image.NewUniform(background), image.Point{}, draw.Src)
+	return &sheetGrid{sheet: sheet, cell: cell, columns: columns, padding: padding, labels: opts.Labels}
+}
+
+// place centers img, if non-nil, in the i-th cell and labels the cell.
+func (sg *sheetGrid) place(i int, img image.Image) {
+	cellMin := image.Pt(
+		sg.padding+(i%sg.columns)*(sg.cell.X+sg.padding),
+		sg.padding+(i/sg.columns)*(sg.cell.Y+sg.padding))
+	if img != nil {
+		bounds := img.Bounds()
+		offset := image.Pt((sg.cell.X-bounds.Dx())/2, (sg.cell.Y-bounds.Dy())/2)
+		dst := image.Rectangle{Min: cellMin.Add(offset), Max: cellMin.Add(offset).Add(bounds.Size())}
+		draw.Draw(sg.sheet, dst, img, bounds.Min, draw.Over)
+	}
+	if sg.labels {
+		drawLabel(sg.sheet, image.Rectangle{Min: cellMin, Max: cellMin.Add(sg.cell)}, strconv.Itoa(i))
+	}
+}
+
+// drawLabel writes label in white on a dark box
+// in the bottom left corner of cell.
+func drawLabel(dst *image.RGBA, cell image.Rectangle, label string) {
+	size := textSize(label, 1)
+	box := image.Rectangle{
+		Min: image.Pt(cell.Min.X, cell.Max.Y-size.Y-4),
+		Max: image.Pt(cell.Min.X+size.X+4, cell.Max.Y),
+	}.Intersect(cell)
+	draw.Draw(dst, box, image.NewUniform(color.RGBA{A: 0xb0}), image.Point{}, draw.Over)
+	drawText(dst, box.Min.Add(image.Pt(2, 2)), label, 1, color.White)
+}
+
+// ContactSheet decodes the rendition of giph chosen by opts and lays
+// out all its frames, so that an animation can be reviewed at a glance.
+func (c *Client) ContactSheet(ctx context.Context, giph *Giph, opts *SheetOptions) (*image.RGBA, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).ContactSheet")
+	defer span.End()
+
+	if opts == nil {
+		opts = new(SheetOptions)
+	}
+	r := opts.Rendition
+	if r == "" {
+		r = RenditionOriginal
+	}
+	g, err := c.decodeRendition(ctx, giph, r)
+	if err != nil {
+		return nil, err
+	}
+	return ContactSheet(g, opts)
+}
+
+// PageSheet lays out the first frame of every Giph of page in a grid,
+// labeled by their index in page.Giphs. The frames that fail to
+// download leave their cells blank and are reported in the error,
+// which is returned along with the rest of the sheet.
+func (c *Client) PageSheet(ctx context.Context, page *Page, opts *SheetOptions) (*image.RGBA, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).PageSheet")
+	defer span.End()
+
+	if page == nil || len(page.Giphs) == 0 {
+		return nil, errNoSheetImages
+	}
+	if opts == nil {
+		opts = new(SheetOptions)
+	}
+	maxWidth, maxHeight := opts.CellWidth, opts.CellHeight
+	if maxWidth <= 0 && maxHeight <= 0 {
+		maxWidth, maxHeight = DefaultCellSize, DefaultCellSize
+	}
+
+	frames := make([]image.Image, len(page.Giphs))
+	var errs []error
+	for i, giph := range page.Giphs {
+		frame, err := c.Frame(ctx, giph, &ThumbnailOptions{Rendition: opts.Rendition, Width: maxWidth, Height: maxHeight})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("giph #%d: %w", i, err))
+			continue
+		}
+		frames[i] = frame
+	}
+	sheet, err := layoutSheet(frames, maxWidth, maxHeight, opts)
+	if err != nil {
+		return nil, err
+	}
+	return sheet, errors.Join(errs...)
+}
@@ -0,0 +1,180 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestContactSheet(t *testing.T) {
+	// cellCenter is where the middle of frame i lands on the sheet.
+	type cellCenter struct {
+		x, y int
+		want color.RGBA
+	}
+	tests := [...]struct {
+		opts    *giphy.SheetOptions
+		want    image.Rectangle
+		centers []cellCenter
+	}{
+		0: {
+			// Five 40x30 frames fit in 3 columns and 2 rows.
+			want: image.Rect(0, 0, 120, 60),
+			centers: []cellCenter{
+				{x: 20, y: 15, want: red},
+				{x: 45, y: 15, want: blue},
+				{x: 100, y: 15, want: green},
+				{x: 60, y: 45, want: white},
+				// The sixth cell is blank.
+				{x: 100, y: 45, want: white},
+			},
+		},
+		1: {
+			opts: &giphy.SheetOptions{Columns: 5, Padding: 2, CellHeight: 15, Background: color.Black},
+			want: image.Rect(0, 0, 5*(20+2)+2, 15+2*2),
+			centers: []cellCenter{
+				{x: 12, y: 9, want: red},
+				{x: 2 + 2*22 + 10, y: 9, want: green},
+				{x: 2 + 4*22 + 10, y: 9, want: white},
+				{x: 1, y: 1, want: color.RGBA{A: 0xff}},
+			},
+		},
+		2: {
+			opts: &giphy.SheetOptions{Columns: 9},
+			want: image.Rect(0, 0, 200, 30),
+			centers: []cellCenter{
+				{x: 180, y: 15, want: white},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		sheet, err := giphy.ContactSheet(storyboardGIF(), tt.opts)
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if got := sheet.Bounds(); got != tt.want {
+			t.Errorf("#%d: got bounds %v want %v", i, got, tt.want)
+		}
+		for _, center := range tt.centers {
+			if got := rgbaAt(sheet, center.x, center.y); got != center.want {
+				t.Errorf("#%d: at (%d, %d): got %v want %v", i, center.x, center.y, got, center.want)
+			}
+		}
+	}
+
+	if _, err := giphy.ContactSheet(&gif.GIF{}, nil); err == nil {
+		t.Error("expected an error for a GIF without frames")
+	}
+}
+
+func TestContactSheetLabels(t *testing.T) {
+	sheet, err := giphy.ContactSheet(storyboardGIF(), &giphy.SheetOptions{Labels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The label of the first frame is a "0" on a dark box along the
+	// bottom of its cell, leaving the rest of the red frame untouched.
+	if got := rgbaAt(sheet, 1, 19); got.R > 0x80 || got.G != 0 {
+		t.Errorf("got %v want a darkened red under the label", got)
+	}
+	if got := rgbaAt(sheet, 2, 21); got != white {
+		t.Errorf("got %v want a white stroke of the 0", got)
+	}
+	if got := rgbaAt(sheet, 20, 10); got != red {
+		t.Errorf("got %v want the frame untouched above the label", got)
+	}
+	// The label of the fifth frame, in the second row.
+	if got := rgbaAt(sheet, 40+2, 30+23); got != white {
+		t.Errorf("got %v want a white stroke of the 4", got)
+	}
+}
+
+func TestClientSheets(t *testing.T) {
+	still := &gif.GIF{
+		Image:  []*image.Paletted{solidFrame(image.Rect(0, 0, 40, 30), magenta)},
+		Delay:  []int{0},
+		Config: image.Config{Width: 40, Height: 30},
+	}
+	server := newPathServer(map[string][]byte{
+		"/anim.gif":  encodeGIF(t, storyboardGIF()),
+		"/still.gif": encodeGIF(t, still),
+	})
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	animated := &giphy.Giph{ID: "animated", Sizes: map[string]*giphy.GIF{
+		"original": {URL: server.URL + "/anim.gif"},
+	}}
+	withStill := &giphy.Giph{ID: "with-still", Sizes: map[string]*giphy.GIF{
+		"original":       {URL: server.URL + "/anim.gif"},
+		"original_still": {URL: server.URL + "/still.gif"},
+	}}
+	missing := &giphy.Giph{ID: "missing", Sizes: map[string]*giphy.GIF{
+		"original": {URL: server.URL + "/missing.gif"},
+	}}
+
+	sheet, err := client.ContactSheet(context.Background(), withStill, &giphy.SheetOptions{Columns: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sheet.Bounds(), image.Rect(0, 0, 200, 30); got != want {
+		t.Errorf("got bounds %v want %v", got, want)
+	}
+	if got, want := server.takeRequested(), []string{"/anim.gif"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got requests %q want %q", got, want)
+	}
+
+	page := &giphy.Page{Giphs: []*giphy.Giph{withStill, animated, missing}}
+	sheet, err = client.PageSheet(context.Background(), page, &giphy.SheetOptions{CellWidth: 20})
+	if err == nil || !strings.Contains(err.Error(), "giph #2") {
+		t.Errorf("got err %v want one for giph #2", err)
+	}
+	if sheet == nil {
+		t.Fatal("expected the sheet despite the missing giph")
+	}
+	if got, want := sheet.Bounds(), image.Rect(0, 0, 40, 30); got != want {
+		t.Errorf("got bounds %v want %v", got, want)
+	}
+	centers := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{x: 10, y: 7, want: magenta},
+		{x: 30, y: 7, want: red},
+		{x: 10, y: 22, want: white},
+	}
+	for _, center := range centers {
+		if got := rgbaAt(sheet, center.x, center.y); !closeColors(got, center.want, 8) {
+			t.Errorf("at (%d, %d): got %v want %v", center.x, center.y, got, center.want)
+		}
+	}
+
+	if _, err := client.PageSheet(context.Background(), &giphy.Page{}, nil); err == nil {
+		t.Error("expected an error for an empty page")
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type DecodeWarningKind string
+
+const (
+	WarnUnknownField DecodeWarningKind = "unknown_field"
+	WarnTypeMismatch DecodeWarningKind = "type_mismatch"
+)
+
+// DecodeWarning describes a part of a response that
+// did not match the types that this package decodes into.
+type DecodeWarning struct {
+	Kind DecodeWarningKind `json:"kind"`
+	// Path locates the offending value e.g. "data[3].images.original.width".
+	Path   string `json:"path"`
+	Detail string `json:"detail"`
+}
+
+func (dw *DecodeWarning) String() string {
+	return fmt.Sprintf("%s at %q: %s", dw.Kind, dw.Path, dw.Detail)
+}
+
+type DecodeReport struct {
+	URL      string           `json:"url"`
+	Warnings []*DecodeWarning `json:"warnings"`
+}
+
+// SetStrictDecoding toggles the diagnostic decoding of responses. When
+// enabled, unknown fields and type mismatches do not fail requests but
+// are instead reported on each Page and to the DecodeReport handler.
+func (c *Client) SetStrictDecoding(enabled bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.strictDecoding = enabled
+}
+
+// SetDecodeReportHandler registers fn to be invoked with the warnings
+// of every response that strict decoding found problems with.
+func (c *Client) SetDecodeReportHandler(fn func(*DecodeReport)) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.onDecodeReport = fn
+}
+
+func (c *Client) decodeSettings() (bool, func(*DecodeReport)) {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.strictDecoding, c.onDecodeReport
+}
+
+// decode unmarshals slurp into save, and in strict
+// mode also diagnoses how slurp differs from save.
+func (c *Client) decode(theURL string, slurp []byte, save interface{}) ([]*DecodeWarning, error) {
+	strict, onReport := c.decodeSettings()
+	if !strict {
+		return nil, json.Unmarshal(slurp, save)
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(slurp, &raw); err != nil {
+		// Not even valid JSON, nothing to diagnose.
+		return nil, err
+	}
+	var warnings []*DecodeWarning
+	inspectJSON("", raw, reflect.TypeOf(save), false, &warnings)
+
+	err := json.Unmarshal(slurp, save)
+	if _, mismatch := err.(*json.UnmarshalTypeError); mismatch {
+		// The mismatch was already diagnosed and json.Unmarshal
+		// still populates the rest of save, so carry on.
+		err = nil
+	}
+	// Even responses that fail to decode are reported, since
+	// the warnings are what explains the failure.
+	if len(warnings) > 0 && onReport != nil {
+		onReport(&DecodeReport{URL: redactAPIKey(theURL), Warnings: warnings})
+	}
+	return warnings, err
+}
+
+func redactAPIKey(theURL string) string {
+	i := strings.Index(theURL, "api_key=")
+	if i < 0 {
+		return theURL
+	}
+	j := strings.IndexByte(theURL[i:], '&')
+	if j < 0 {
+		return theURL[:i] + "api_key=REDACTED"
+	}
+	return theURL[:i] + "api_key=REDACTED" + theURL[i+j:]
+}
+
+var (
+	giphyTimeType = reflect.TypeOf(GiphyTime{})
+	flagType      = reflect.TypeOf(Flag(false))
+)
+
+func jsonKind(raw interface{}) string {
+	switch raw.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return "null"
+	}
+}
+
+// inspectJSON walks raw alongside typ, the way encoding/json would
+// decode it, and appends a DecodeWarning for every discrepancy.
+func inspectJSON(path string, raw interface{}, typ reflect.Type, quoted bool, warnings *[]*DecodeWarning) {
+	if raw == nil {
+		// null is acceptable for any type.
+		return
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	mismatch := func(want string) {
+		*warnings = append(*warnings, &DecodeWarning{
+			Kind:   WarnTypeMismatch,
+			Path:   path,
+			Detail: fmt.Sprintf("got JSON %s, want %s for %s", jsonKind(raw), want, typ),
+		})
+	}
+
+	if quoted {
+		// Fields with the ",string" option carry their values in JSON strings.
+		str, ok := raw.(string)
+		if !ok {
+			mismatch("quoted " + typ.Kind().String())
+			return
+		}
+		if !quotedValueFits(str, typ) {
+			*warnings = append(*warnings, &DecodeWarning{
+				Kind:   WarnTypeMismatch,
+				Path:   path,
+				Detail: fmt.Sprintf("%q cannot be parsed as %s", str, typ),
+			})
+		}
+		return
+	}
+
+	if typ == giphyTimeType {
+		if _, ok := raw.(string); !ok {
+			mismatch("string")
+		}
+		return
+	}
+	if typ == flagType {
+		// Flag accepts 0, 1, booleans and their quoted forms.
+		b, _ := json.Marshal(raw)
+		if err := new(Flag).UnmarshalJSON(b); err != nil {
+			mismatch(`0, 1, bool or one of "0", "1", "true", "false"`)
+		}
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			mismatch("object")
+			return
+		}
+		fields := jsonFields(typ)
+		for key, value := range obj {
+			fieldPath := joinPath(path, key)
+			field := lookupField(fields, key)
+			if field == nil {
+				*warnings = append(*warnings, &DecodeWarning{
+					Kind:   WarnUnknownField,
+					Path:   fieldPath,
+					Detail: fmt.Sprintf("%s has no field for JSON %s", typ, jsonKind(value)),
+				})
+				continue
+			}
+			inspectJSON(fieldPath, value, field.typ, field.quoted, warnings)
+		}
+
+	case reflect.Map:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			mismatch("object")
+			return
+		}
+		for key, value := range obj {
+			inspectJSON(joinPath(path, key), value, typ.Elem(), false, warnings)
+		}
+
+	case reflect.Slice, reflect.Array:
+		arr, ok := raw.([]interface{})
+		if !ok {
+			mismatch("array")
+			return
+		}
+		for i, value := range arr {
+			inspectJSON(fmt.Sprintf("%s[%d]", path, i), value, typ.Elem(), false, warnings)
+		}
+
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			mismatch("string")
+		}
+
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			mismatch("bool")
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := raw.(float64); !ok {
+			mismatch("number")
+		}
+	}
+}
+
+func quotedValueFits(str string, typ reflect.Type) bool {
+	var err error
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(str, 10, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(str, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(str, typ.Bits())
+	case reflect.Bool:
+		_, err = strconv.ParseBool(str)
+	}
+	return err == nil
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+type jsonField struct {
+	name   string
+	typ    reflect.Type
+	quoted bool
+	depth  int
+	tagged bool
+}
+
+// jsonFields lists the fields that encoding/json decodes into typ,
+// promoting those of untagged embedded structs. Of the fields sharing a
+// name, the shallowest wins, then the tagged one; any others are ambiguous
+// and, as encoding/json does, dropped.
+func jsonFields(typ reflect.Type) []*jsonField {
+	var all []*jsonField
+	embedding := map[reflect.Type]bool{typ: true}
+	var walk func(typ reflect.Type, depth int)
+	walk = func(typ reflect.Type, depth int) {
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			ft := sf.Type
+			if sf.Anonymous && ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if !sf.IsExported() && !(sf.Anonymous && ft.Kind() == reflect.Struct) {
+				// Only the exported fields of unexported embedded structs decode.
+				continue
+			}
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts := tag, ""
+			if j := strings.IndexByte(tag, ','); j >= 0 {
+				name, opts = tag[:j], tag[j:]
+			}
+			if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				if !embedding[ft] {
+					embedding[ft] = true
+					walk(ft, depth+1)
+					delete(embedding, ft)
+				}
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			field := &jsonField{name: name, typ: sf.Type, quoted: strings.Contains(opts, ",string"), depth: depth, tagged: name != ""}
+			if name == "" {
+				field.name = sf.Name
+			}
+			all = append(all, field)
+		}
+	}
+	walk(typ, 0)
+
+	byName := make(map[string][]*jsonField)
+	for _, field := range all {
+		byName[field.name] = append(byName[field.name], field)
+	}
+	var fields []*jsonField
+	for _, field := range all {
+		if dominantField(byName[field.name]) == field {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func dominantField(fields []*jsonField) *jsonField {
+	var dominant *jsonField
+	ambiguous := false
+	for _, field := range fields {
+		switch {
+		case dominant == nil, field.depth < dominant.depth,
+			field.depth == dominant.depth && field.tagged && !dominant.tagged:
+			dominant, ambiguous = field, false
+		case field.depth == dominant.depth && field.tagged == dominant.tagged:
+			ambiguous = true
+		}
+	}
+	if ambiguous {
+		return nil
+	}
+	return dominant
+}
+
+// lookupField finds the field that encoding/json decodes key into,
+// preferring an exact match to a case-insensitive one.
+func lookupField(fields []*jsonField, key string) *jsonField {
+	var folded *jsonField
+	for _, field := range fields {
+		if field.name == key {
+			return field
+		}
+		if folded == nil && strings.EqualFold(field.name, key) {
+			folded = field
+		}
+	}
+	return folded
+}
@@ -0,0 +1,204 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: driftRoute})
+
+	// Without strict decoding, the mismatched width fails the page.
+	res, err := client.Search(context.Background(), &giphy.Request{Query: "dance"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for page := range res.Pages {
+		if page.Err == nil {
+			t.Errorf("Page #%d: expected a decoding error", page.PageNumber)
+		}
+	}
+
+	var mu sync.Mutex
+	var reports []*giphy.DecodeReport
+	client.SetStrictDecoding(true)
+	client.SetDecodeReportHandler(func(report *giphy.DecodeReport) {
+		mu.Lock()
+		reports = append(reports, report)
+		mu.Unlock()
+	})
+
+	res, err = client.Search(context.Background(), &giphy.Request{Query: "dance"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages []*giphy.Page
+	for page := range res.Pages {
+		pages = append(pages, page)
+	}
+	if len(pages) != 1 {
+		t.Fatalf("gotPages: %d wantPages: 1", len(pages))
+	}
+	page := pages[0]
+	if page.Err != nil {
+		t.Fatalf("strict decoding must not fail the page, got: %v", page.Err)
+	}
+	if len(page.Giphs) != 1 || page.Giphs[0].ID != "xT9IgG50Fb7Mi0prBC" {
+		t.Fatalf("unexpected giphs: %#v", page.Giphs)
+	}
+	if got := page.Giphs[0].Images.Original.Height; got != 270 {
+		t.Errorf("the rest of the giph must still be decoded, gotHeight: %d", got)
+	}
+
+	var got []string
+	for _, warning := range page.Warnings {
+		got = append(got, string(warning.Kind)+" "+warning.Path)
+	}
+	sort.Strings(got)
+	want := []string{
+		"type_mismatch data[0].images.original.width",
+		"unknown_field data[0].analytics_response_payload",
+		"unknown_field data[0].cta",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("gotWarnings:\n%s\nwantWarnings:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) != 1 {
+		t.Fatalf("gotReports: %d wantReports: 1", len(reports))
+	}
+	if strings.Contains(reports[0].URL, testAPIKey1) {
+		t.Errorf("the API key must be redacted from %q", reports[0].URL)
+	}
+	if len(reports[0].Warnings) != len(page.Warnings) {
+		t.Errorf("gotReportWarnings: %d wantReportWarnings: %d", len(reports[0].Warnings), len(page.Warnings))
+	}
+}
+
+func TestStrictDecodingKnownFixtures(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&transport{route: searchRoute})
+	client.SetStrictDecoding(true)
+
+	res, err := client.Search(context.Background(), &giphy.Request{Query: "hip hop", MaxPageNumber: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for page := range res.Pages {
+		if page.Err != nil {
+			t.Fatalf("Page #%d err: %v", page.PageNumber, page.Err)
+		}
+		// Every field of the fixtures, down to
+		// the hash of renditions, is decoded.
+		for _, warning := range page.Warnings {
+			t.Errorf("Page #%d: unexpected warning: %v", page.PageNumber, warning)
+		}
+	}
+}
+
+// staticTransport responds to every request with body.
+type staticTransport struct {
+	body string
+}
+
+func (st *staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return makeResp("200 OK", http.StatusOK, ioutil.NopCloser(strings.NewReader(st.body))), nil
+}
+
+// strictWarnings fetches a Giph out of body in strict mode, returning
+// the "kind path" of each warning, sorted. Values that the Giph cannot
+// hold at all fail the fetch, so only their warnings are checked.
+func strictWarnings(t *testing.T, body string) []string {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&staticTransport{body: body})
+	client.SetStrictDecoding(true)
+	var got []string
+	client.SetDecodeReportHandler(func(report *giphy.DecodeReport) {
+		for _, warning := range report.Warnings {
+			got = append(got, string(warning.Kind)+" "+warning.Path)
+		}
+	})
+	if _, err := client.GIFByID(context.Background(), "strict"); err != nil && len(got) == 0 {
+		t.Fatalf("GIFByID failed without warnings: %v", err)
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestStrictDecodingFlags(t *testing.T) {
+	tests := [...]struct {
+		flag string
+		want []string
+	}{
+		0: {flag: `1`},
+		1: {flag: `false`},
+		2: {flag: `"1"`},
+		3: {flag: `"0"`},
+		4: {flag: `"true"`},
+		5: {flag: `"false"`},
+		6: {flag: `"yes"`, want: []string{"type_mismatch data.is_sticker"}},
+		7: {flag: `2`, want: []string{"type_mismatch data.is_sticker"}},
+		8: {flag: `{}`, want: []string{"type_mismatch data.is_sticker"}},
+	}
+
+	for i, tt := range tests {
+		got := strictWarnings(t, `{"data": {"id": "strict", "is_sticker": `+tt.flag+`}}`)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: %s: gotWarnings: %q wantWarnings: %q", i, tt.flag, got, tt.want)
+		}
+	}
+}
+
+func TestStrictDecodingKeyCase(t *testing.T) {
+	tests := [...]struct {
+		data string
+		want []string
+	}{
+		0: {data: `{"id": "strict", "is_sticker": 1}`},
+		// encoding/json matches keys case-insensitively.
+		1: {data: `{"ID": "strict", "Is_Sticker": 1, "IMAGES": {"Original": {"URL": "https://giphy.com/a.gif"}}}`},
+		2: {data: `{"Id": "strict", "Is_Sticker": "yes"}`, want: []string{"type_mismatch data.Is_Sticker"}},
+		3: {data: `{"id": "strict", "is-sticker": 1}`, want: []string{"unknown_field data.is-sticker"}},
+	}
+
+	for i, tt := range tests {
+		got := strictWarnings(t, `{"data": `+tt.data+`}`)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: gotWarnings: %q wantWarnings: %q", i, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"go.opencensus.io/trace"
+)
+
+// FrameChoice picks which frame of an animation stands in for it.
+type FrameChoice int
+
+const (
+	FrameFirst FrameChoice = iota
+	FrameMiddle
+	// FrameRepresentative is the frame with the highest color
+	// variance, which skips blank intro and outro frames.
+	FrameRepresentative
+)
+
+type ImageFormat string
+
+const (
+	ImagePNG  ImageFormat = "png"
+	ImageJPEG ImageFormat = "jpeg"
+)
+
+type ThumbnailOptions struct {
+	// Rendition is the animation to take the frame from,
+	// defaulting to RenditionOriginal.
+	Rendition Rendition `json:"rendition,omitempty"`
+
+	Frame FrameChoice `json:"frame,omitempty"`
+
+	// Width and Height bound the thumbnail, whose aspect ratio is
+	// preserved. Zero leaves that dimension unconstrained.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// Format defaults to ImagePNG. JPEGs are flattened onto white
+	// and encoded with Quality, defaulting to jpeg.DefaultQuality.
+	Format  ImageFormat `json:"format,omitempty"`
+	Quality int         `json:"quality,omitempty"`
+}
+
+var (
+	errNoFrames           = errors.New("the GIF has no frames")
+	errUnknownImageFormat = errors.New("unknown image format")
+)
+
+// compositeFrames draws the frames of g in order onto a canvas the size
+// of its logical screen, honoring each frame's disposal method. visit is
+// called with the canvas as it is displayed during frame i and may stop
+// the walk by returning false. The canvas is reused between calls.
+func compositeFrames(g *gif.GIF, visit func(i int, canvas *image.RGBA) bool) {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() && len(g.Image) > 0 {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+	var previous *image.RGBA
+	for i, frame := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		if !visit(i, canvas) {
+			return
+		}
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous.Pix)
+		}
+	}
+}
+
+// ExtractFrame returns the chosen frame of g as it is displayed,
+// that is composited over the frames before it.
+func ExtractFrame(g *gif.GIF, choice FrameChoice) (*image.RGBA, error) {
+	if g == nil || len(g.Image) == 0 {
+		return nil, errNoFrames
+	}
+
+	target := 0
+	switch choice {
+	case FrameFirst:
+	case FrameMiddle:
+		target = len(g.Image) / 2
+	case FrameRepresentative:
+		target = -1
+	default:
+		return nil, fmt.Errorf("unknown frame choice %d", choice)
+	}
+
+	var chosen *image.RGBA
+	bestVariance := -1.0
+	compositeFrames(g, func(i int, canvas *image.RGBA) bool {
+		if target >= 0 {
+			if i < target {
+				return true
+			}
+			chosen = cloneRGBA(canvas)
+			return false
+		}
+		if variance := colorVariance(canvas); variance > bestVariance {
+			bestVariance = variance
+			chosen = cloneRGBA(canvas)
+		}
+		return true
+	})
+	return chosen, nil
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	clone := image.NewRGBA(img.Rect)
+	copy(clone.Pix, img.Pix)
+	return clone
+}
+
+// colorVariance sums the variances of the red, green and blue
+// channels over a grid of at most 64x64 samples of img.
+func colorVariance(img *image.RGBA) float64 {
+	bounds := img.Bounds()
+	stepX := max(bounds.Dx()/64, 1)
+	stepY := max(bounds.Dy()/64, 1)
+	var n float64
+	var sum, sumSquares [3]float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			px := img.Pix[img.PixOffset(x, y):]
+			for c := 0; c < 3; c++ {
+				v := float64(px[c])
+				sum[c] += v
+				sumSquares[c] += v * v
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	var variance float64
+	for c := 0; c < 3; c++ {
+		mean := sum[c] / n
+		variance += sumSquares[c]/n - mean*mean
+	}
+	return variance
+}
+
+// fitWithin scales width by height to fit within maxWidth by maxHeight,
+// preserving the aspect ratio. A zero bound leaves that side unconstrained.
+func fitWithin(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return width, height
+	}
+	scale := math.Inf(1)
+	if maxWidth > 0 {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 {
+		scale = math.Min(scale, float64(maxHeight)/float64(height))
+	}
+	if math.IsInf(scale, 1) {
+		return width, height
+	}
+	w := max(int(math.Round(float64(width)*scale)), 1)
+	h := max(int(math.Round(float64(height)*scale)), 1)
+	return w, h
+}
+
+// Resize resamples src to width by height with a triangle filter that
+// widens when downscaling, so that every source pixel contributes.
+func Resize(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+	if width <= 0 || height <= 0 || bounds.Empty() {
+		return dst
+	}
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(bounds)
+		draw.Draw(rgba, bounds, src, bounds.Min, draw.Src)
+	}
+
+	// Resample the rows into tmp, then the columns of tmp into dst.
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	tmp := make([]float32, srcH*width*4)
+	for _, col := range resampleWeights(srcW, width) {
+		for y := 0; y < srcH; y++ {
+			var acc [4]float32
+			offset := rgba.PixOffset(bounds.Min.X+col.start, bounds.Min.Y+y)
+			for k, weight := range col.weights {
+				px := rgba.Pix[offset+4*k:]
+				for c := range acc {
+					acc[c] += weight * float32(px[c])
+				}
+			}
+			copy(tmp[(y*width+col.index)*4:], acc[:])
+		}
+	}
+	for _, row := range resampleWeights(srcH, height) {
+		for x := 0; x < width; x++ {
+			var acc [4]float32
+			for k, weight := range row.weights {
+				px := tmp[((row.start+k)*width+x)*4:]
+				for c := range acc {
+					acc[c] += weight * px[c]
+				}
+			}
+			px := dst.Pix[dst.PixOffset(x, row.index):]
+			for c, v := range acc {
+				px[c] = uint8(math.Max(0, math.Min(255, math.Round(float64(v)))))
+			}
+		}
+	}
+	return dst
+}
+
+// resampleContribution holds the weights of the consecutive
+// source pixels from start that make up destination pixel index.
+type resampleContribution struct {
+	index   int
+	start   int
+	weights []float32
+}
+
+func resampleWeights(srcSize, dstSize int) []resampleContribution {
+	scale := float64(srcSize) / float64(dstSize)
+	support := math.Max(scale, 1)
+	contribs := make([]resampleContribution, dstSize)
+	for i := range contribs {
+		center := (float64(i)+0.5)*scale - 0.5
+		start := max(int(math.Ceil(center-support)), 0)
+		end := min(int(math.Floor(center+support)), srcSize-1)
+		if end < start {
+			// Upscaling past the edge: use the nearest source pixel.
+			start = min(max(int(math.Round(center)), 0), srcSize-1)
+			end = start
+		}
+		weights := make([]float32, end-start+1)
+		var total float64
+		for j := start; j <= end; j++ {
+			weight := math.Max(0, 1-math.Abs(float64(j)-center)/support)
+			weights[j-start] = float32(weight)
+			total += weight
+		}
+		if total == 0 {
+			weights[0], total = 1, 1
+		}
+		for k := range weights {
+			weights[k] /= float32(total)
+		}
+		contribs[i] = resampleContribution{index: i, start: start, weights: weights}
+	}
+	return contribs
+}
+
+// EncodeImage writes img to w in format, defaulting to ImagePNG.
+func EncodeImage(w io.Writer, img image.Image, format ImageFormat, quality int) error {
+	switch format {
+	case ImagePNG, "":
+		return png.Encode(w, img)
+	case ImageJPEG:
+		// JPEG has no alpha channel so flatten onto white.
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Rect, img, img.Bounds().Min, draw.Over)
+		if quality <= 0 {
+			quality = jpeg.DefaultQuality
+		}
+		return jpeg.Encode(w, flat, &jpeg.Options{Quality: quality})
+	default:
+		return errUnknownImageFormat
+	}
+}
+
+// stillOf returns the still counterpart of rendition r e.g.
+// "fixed_height_still" for "fixed_height", if giph has it.
+func stillOf(giph *Giph, r Rendition) (Rendition, bool) {
+	still := r + "_still"
+	if r.IsStill() {
+		still = r
+	}
+	size := giph.Rendition(still)
+	return still, size != nil && size.URL != ""
+}
+
+// Frame returns the chosen frame of a Giph resized to fit opts. The
+// first frame comes from the rendition's still counterpart when GIPHY
+// has one, falling back to decoding the animation when it is missing
+// or fails to download.
+func (c *Client) Frame(ctx context.Context, giph *Giph, opts *ThumbnailOptions) (image.Image, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Frame")
+	defer span.End()
+
+	if opts == nil {
+		opts = new(ThumbnailOptions)
+	}
+	if giph == nil {
+		return nil, errNilGiph
+	}
+	r := opts.Rendition
+	if r == "" {
+		r = RenditionOriginal
+	}
+
+	var frame *image.RGBA
+	if still, ok := stillOf(giph, r); ok && opts.Frame == FrameFirst {
+		if g, err := c.decodeRendition(ctx, giph, still); err == nil {
+			frame, _ = ExtractFrame(g, FrameFirst)
+		}
+	}
+	if frame == nil {
+		g, err := c.decodeRendition(ctx, giph, r)
+		if err != nil {
+			return nil, err
+		}
+		if frame, err = ExtractFrame(g, opts.Frame); err != nil {
+			return nil, err
+		}
+	}
+
+	width, height := fitWithin(frame.Rect.Dx(), frame.Rect.Dy(), opts.Width, opts.Height)
+	if width == frame.Rect.Dx() && height == frame.Rect.Dy() {
+		return frame, nil
+	}
+	return Resize(frame, width, height), nil
+}
+
+// Thumbnail writes the frame chosen by opts to w, encoded in opts.Format.
+func (c *Client) Thumbnail(ctx context.Context, giph *Giph, w io.Writer, opts *ThumbnailOptions) error {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Thumbnail")
+	defer span.End()
+
+	if opts == nil {
+		opts = new(ThumbnailOptions)
+	}
+	switch opts.Format {
+	case ImagePNG, ImageJPEG, "":
+	default:
+		return errUnknownImageFormat
+	}
+	frame, err := c.Frame(ctx, giph, opts)
+	if err != nil {
+		return err
+	}
+	return EncodeImage(w, frame, opts.Format, opts.Quality)
+}
@@ -0,0 +1,348 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func solidFrame(rect image.Rectangle, c color.Color) *image.Paletted {
+	frame := image.NewPaletted(rect, palette.Plan9)
+	fill := uint8(frame.Palette.Index(c))
+	for i := range frame.Pix {
+		frame.Pix[i] = fill
+	}
+	return frame
+}
+
+var (
+	red     = color.RGBA{R: 0xff, A: 0xff}
+	green   = color.RGBA{G: 0xff, A: 0xff}
+	blue    = color.RGBA{B: 0xff, A: 0xff}
+	white   = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	magenta = color.RGBA{R: 0xff, B: 0xff, A: 0xff}
+)
+
+// storyboardGIF is a 40x30 animation of a red frame, a blue frame covering
+// only its left half, then green, blue and white frames. Composited, the
+// second frame is the only one with more than one color.
+func storyboardGIF() *gif.GIF {
+	full := image.Rect(0, 0, 40, 30)
+	return &gif.GIF{
+		Image: []*image.Paletted{
+			solidFrame(full, red),
+			solidFrame(image.Rect(0, 0, 20, 30), blue),
+			solidFrame(full, green),
+			solidFrame(full, blue),
+			solidFrame(full, white),
+		},
+		Delay:    []int{10, 10, 10, 10, 10},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalNone, gif.DisposalNone, gif.DisposalNone, gif.DisposalNone},
+		Config:   image.Config{Width: 40, Height: 30},
+	}
+}
+
+func encodeGIF(t *testing.T, g *gif.GIF) []byte {
+	buf := new(bytes.Buffer)
+	if err := gif.EncodeAll(buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func rgbaAt(img image.Image, x, y int) color.RGBA {
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+func TestExtractFrame(t *testing.T) {
+	tests := [...]struct {
+		choice      giphy.FrameChoice
+		left, right color.RGBA
+		wantErr     bool
+	}{
+		0: {choice: giphy.FrameFirst, left: red, right: red},
+		1: {choice: giphy.FrameMiddle, left: green, right: green},
+		2: {choice: giphy.FrameRepresentative, left: blue, right: red},
+		3: {choice: giphy.FrameChoice(42), wantErr: true},
+	}
+
+	for i, tt := range tests {
+		frame, err := giphy.ExtractFrame(storyboardGIF(), tt.choice)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected an error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if got := frame.Bounds(); got != image.Rect(0, 0, 40, 30) {
+			t.Errorf("#%d: got bounds %v", i, got)
+		}
+		if got := rgbaAt(frame, 5, 15); got != tt.left {
+			t.Errorf("#%d: left: got %v want %v", i, got, tt.left)
+		}
+		if got := rgbaAt(frame, 35, 15); got != tt.right {
+			t.Errorf("#%d: right: got %v want %v", i, got, tt.right)
+		}
+	}
+
+	if _, err := giphy.ExtractFrame(&gif.GIF{}, giphy.FrameFirst); err == nil {
+		t.Error("expected an error for a GIF without frames")
+	}
+}
+
+func TestExtractFrameDisposal(t *testing.T) {
+	// The middle frame of five now only covers the right half, showing
+	// what the blue frame before it left behind on the left.
+	g := storyboardGIF()
+	g.Image[2] = solidFrame(image.Rect(20, 0, 40, 30), green)
+
+	tests := [...]struct {
+		disposal byte
+		left     color.RGBA
+	}{
+		0: {disposal: gif.DisposalNone, left: blue},
+		1: {disposal: gif.DisposalBackground, left: color.RGBA{}},
+		2: {disposal: gif.DisposalPrevious, left: red},
+	}
+
+	for i, tt := range tests {
+		g.Disposal[1] = tt.disposal
+		frame, err := giphy.ExtractFrame(g, giphy.FrameMiddle)
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if got := rgbaAt(frame, 5, 15); got != tt.left {
+			t.Errorf("#%d: left: got %v want %v", i, got, tt.left)
+		}
+		if got := rgbaAt(frame, 35, 15); got != green {
+			t.Errorf("#%d: right: got %v want %v", i, got, green)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	checker := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if (x+y)%2 == 0 {
+				checker.Set(x, y, white)
+			} else {
+				checker.Set(x, y, color.Black)
+			}
+		}
+	}
+	solid := solidFrame(image.Rect(0, 0, 9, 7), magenta)
+
+	tests := [...]struct {
+		src           image.Image
+		width, height int
+		want          color.RGBA
+		tolerance     int
+	}{
+		0: {src: solid, width: 3, height: 2, want: magenta},
+		1: {src: solid, width: 30, height: 20, want: magenta},
+		2: {src: checker, width: 1, height: 1, want: color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}, tolerance: 1},
+		// The filter overlaps neighboring cells so a checkerboard
+		// only averages out to roughly gray.
+		3: {src: checker, width: 2, height: 2, want: color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}, tolerance: 4},
+	}
+
+	for i, tt := range tests {
+		got := giphy.Resize(tt.src, tt.width, tt.height)
+		if b := got.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("#%d: got bounds %v", i, b)
+			continue
+		}
+		for y := 0; y < tt.height; y++ {
+			for x := 0; x < tt.width; x++ {
+				if c := rgbaAt(got, x, y); !closeColors(c, tt.want, tt.tolerance) {
+					t.Errorf("#%d: (%d, %d): got %v want %v", i, x, y, c, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func closeColors(a, b color.RGBA, tolerance int) bool {
+	diff := func(x, y uint8) bool {
+		d := int(x) - int(y)
+		return d <= tolerance && d >= -tolerance
+	}
+	return diff(a.R, b.R) && diff(a.G, b.G) && diff(a.B, b.B) && diff(a.A, b.A)
+}
+
+// pathServer serves the bodies by URL path, with 404s for the rest,
+// and records the paths that were requested.
+type pathServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requested []string
+}
+
+func newPathServer(bodies map[string][]byte) *pathServer {
+	ps := new(pathServer)
+	ps.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ps.mu.Lock()
+		ps.requested = append(ps.requested, req.URL.Path)
+		ps.mu.Unlock()
+
+		body, ok := bodies[req.URL.Path]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+		rw.Header().Set("Content-Type", "image/gif")
+		rw.Write(body)
+	}))
+	return ps
+}
+
+func (ps *pathServer) takeRequested() []string {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	requested := ps.requested
+	ps.requested = nil
+	return requested
+}
+
+func TestClientThumbnail(t *testing.T) {
+	still := &gif.GIF{
+		Image:  []*image.Paletted{solidFrame(image.Rect(0, 0, 40, 30), magenta)},
+		Delay:  []int{0},
+		Config: image.Config{Width: 40, Height: 30},
+	}
+	server := newPathServer(map[string][]byte{
+		"/anim.gif":  encodeGIF(t, storyboardGIF()),
+		"/still.gif": encodeGIF(t, still),
+	})
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withStill := func(stillPath string) *giphy.Giph {
+		giph := &giphy.Giph{ID: "storyboard", Sizes: map[string]*giphy.GIF{
+			"original": {URL: server.URL + "/anim.gif"},
+		}}
+		if stillPath != "" {
+			giph.Sizes["original_still"] = &giphy.GIF{URL: server.URL + stillPath}
+		}
+		return giph
+	}
+
+	tests := [...]struct {
+		giph          *giphy.Giph
+		opts          *giphy.ThumbnailOptions
+		wantRequested []string
+		wantWidth     int
+		wantHeight    int
+		wantColor     color.RGBA
+		wantErr       bool
+	}{
+		0: {
+			giph:          withStill("/still.gif"),
+			wantRequested: []string{"/still.gif"},
+			wantWidth:     40, wantHeight: 30, wantColor: magenta,
+		},
+		1: {
+			giph:          withStill(""),
+			opts:          &giphy.ThumbnailOptions{Width: 10},
+			wantRequested: []string{"/anim.gif"},
+			wantWidth:     10, wantHeight: 8, wantColor: red,
+		},
+		2: {
+			// The still is missing from GIPHY's CDN.
+			giph:          withStill("/gone.gif"),
+			opts:          &giphy.ThumbnailOptions{Width: 20, Height: 5},
+			wantRequested: []string{"/gone.gif", "/anim.gif"},
+			wantWidth:     7, wantHeight: 5, wantColor: red,
+		},
+		3: {
+			giph:          withStill("/still.gif"),
+			opts:          &giphy.ThumbnailOptions{Frame: giphy.FrameMiddle, Height: 15, Format: giphy.ImageJPEG},
+			wantRequested: []string{"/anim.gif"},
+			wantWidth:     20, wantHeight: 15, wantColor: green,
+		},
+		4: {
+			giph:    withStill("/still.gif"),
+			opts:    &giphy.ThumbnailOptions{Format: "bmp"},
+			wantErr: true,
+		},
+		5: {
+			giph:          withStill(""),
+			opts:          &giphy.ThumbnailOptions{Rendition: giphy.RenditionFixedHeight},
+			wantRequested: nil,
+			wantErr:       true,
+		},
+	}
+
+	for i, tt := range tests {
+		buf := new(bytes.Buffer)
+		err := client.Thumbnail(context.Background(), tt.giph, buf, tt.opts)
+		if got := server.takeRequested(); !reflect.DeepEqual(got, tt.wantRequested) {
+			t.Errorf("#%d: got requests %q want %q", i, got, tt.wantRequested)
+		}
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected an error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+
+		var img image.Image
+		if tt.opts != nil && tt.opts.Format == giphy.ImageJPEG {
+			img, err = jpeg.Decode(buf)
+		} else {
+			img, err = png.Decode(buf)
+		}
+		if err != nil {
+			t.Errorf("#%d: decoding the thumbnail: %v", i, err)
+			continue
+		}
+		if b := img.Bounds(); b.Dx() != tt.wantWidth || b.Dy() != tt.wantHeight {
+			t.Errorf("#%d: got %dx%d want %dx%d", i, b.Dx(), b.Dy(), tt.wantWidth, tt.wantHeight)
+		}
+		if got := rgbaAt(img, 1, img.Bounds().Dy()/2); !closeColors(got, tt.wantColor, 8) {
+			t.Errorf("#%d: got color %v want %v", i, got, tt.wantColor)
+		}
+	}
+}
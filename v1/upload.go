@@ -0,0 +1,213 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opencensus.io/trace"
+)
+
+const uploadURL = "https://upload.giphy.com/v1/gifs"
+
+type UploadRequest struct {
+	// Exactly one of File, Path and SourceURL must be set.
+	File      io.Reader `json:"-"`
+	Path      string    `json:"-"`
+	SourceURL string    `json:"source_image_url"`
+
+	// Filename is sent along with File, defaulting to "giphy.gif".
+	Filename string `json:"-"`
+	// Size, if known, is the total number of bytes in File
+	// and it is passed along to OnProgress.
+	Size int64 `json:"-"`
+
+	Tags          []string `json:"tags"`
+	SourcePostURL string   `json:"source_post_url"`
+	Username      string   `json:"username"`
+
+	// OnProgress, if set, is invoked as the file is streamed to
+	// GIPHY with the number of bytes sent so far and the total size,
+	// which is -1 if unknown.
+	OnProgress func(sent, total int64) `json:"-"`
+}
+
+var (
+	errNilUploadRequest  = errors.New("expecting a non-nil UploadRequest")
+	errNoUploadSource    = errors.New("expecting one of File, Path or SourceURL")
+	errManyUploadSources = errors.New("expecting only one of File, Path or SourceURL")
+)
+
+func (ureq *UploadRequest) Validate() error {
+	if ureq == nil {
+		return errNilUploadRequest
+	}
+	n := 0
+	if ureq.File != nil {
+		n += 1
+	}
+	if ureq.Path != "" {
+		n += 1
+	}
+	if ureq.SourceURL != "" {
+		n += 1
+	}
+	switch n {
+	case 0:
+		return errNoUploadSource
+	case 1:
+		return nil
+	default:
+		return errManyUploadSources
+	}
+}
+
+type uploadWrap struct {
+	Data struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+func (c *Client) SetUploadURL(theURL string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.uploadURL = theURL
+}
+
+func (c *Client) _uploadURL() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.uploadURL != "" {
+		return c.uploadURL
+	}
+	return uploadURL
+}
+
+// Upload publishes a GIF to GIPHY and returns the ID that it was assigned.
+// Files are streamed to the server without being buffered in memory.
+func (c *Client) Upload(ctx context.Context, ureq *UploadRequest) (string, error) {
+	ctx, span := trace.StartSpan(ctx, "giphy/v1.(*Client).Upload")
+	defer span.End()
+
+	if err := ureq.Validate(); err != nil {
+		return "", err
+	}
+
+	file, filename, total := ureq.File, ureq.Filename, ureq.Size
+	if ureq.Path != "" {
+		f, err := os.Open(ureq.Path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			return "", err
+		}
+		file, total = f, fi.Size()
+		if filename == "" {
+			filename = filepath.Base(ureq.Path)
+		}
+	}
+	if filename == "" {
+		filename = "giphy.gif"
+	}
+	if total <= 0 {
+		total = -1
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		fields := [...][2]string{
+			{"api_key", c._apiKey()},
+			{"username", ureq.Username},
+			{"source_image_url", ureq.SourceURL},
+			{"source_post_url", ureq.SourcePostURL},
+			{"tags", strings.Join(ureq.Tags, ",")},
+		}
+		for _, kv := range fields {
+			if kv[1] == "" {
+				continue
+			}
+			if err := mw.WriteField(kv[0], kv[1]); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		if file != nil {
+			part, err := mw.CreateFormFile("file", filename)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			src := file
+			if ureq.OnProgress != nil {
+				src = &progressReader{r: file, total: total, onProgress: ureq.OnProgress}
+			}
+			if _, err := io.Copy(part, src); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(mw.Close())
+	}()
+
+	httpReq, err := http.NewRequest("POST", c._uploadURL(), pr)
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", mw.FormDataContentType())
+	slurp, _, err := c.doHTTPReq(ctx, httpReq)
+	if err != nil {
+		return "", err
+	}
+	uWrap := new(uploadWrap)
+	if err := json.Unmarshal(slurp, uWrap); err != nil {
+		return "", err
+	}
+	if uWrap.Data.ID == "" {
+		return "", errEmptyResponse
+	}
+	return uWrap.Data.ID, nil
+}
+
+type progressReader struct {
+	r          io.Reader
+	sent       int64
+	total      int64
+	onProgress func(sent, total int64)
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	if n > 0 {
+		pr.sent += int64(n)
+		pr.onProgress(pr.sent, pr.total)
+	}
+	return n, err
+}
@@ -0,0 +1,152 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func uploadServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			http.Error(rw, "only POST is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.FormValue("api_key") != testAPIKey1 {
+			http.Error(rw, `expecting "api_key"`, http.StatusUnauthorized)
+			return
+		}
+		if got, want := req.FormValue("tags"), "orijtech,go"; got != want {
+			http.Error(rw, fmt.Sprintf("got tags %q want %q", got, want), http.StatusBadRequest)
+			return
+		}
+		id := "from-url"
+		if f, _, err := req.FormFile("file"); err == nil {
+			slurp, _ := ioutil.ReadAll(f)
+			f.Close()
+			id = fmt.Sprintf("from-file-%d", len(slurp))
+		} else if req.FormValue("source_image_url") == "" {
+			http.Error(rw, `expecting "file" or "source_image_url"`, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(rw, `{"data":{"id":%q},"meta":{"status":200,"msg":"OK"}}`, id)
+	}))
+}
+
+func TestUpload(t *testing.T) {
+	server := uploadServer()
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetUploadURL(server.URL)
+
+	body := bytes.Repeat([]byte("GIF89a"), 4096)
+	var lastSent, lastTotal int64
+
+	tests := [...]struct {
+		req     *giphy.UploadRequest
+		wantID  string
+		wantErr bool
+	}{
+		0: {req: nil, wantErr: true},
+		1: {req: &giphy.UploadRequest{Tags: []string{"orijtech", "go"}}, wantErr: true},
+		2: {
+			req: &giphy.UploadRequest{
+				File:      bytes.NewReader(body),
+				SourceURL: "https://example.com/a.gif",
+			},
+			wantErr: true,
+		},
+		3: {
+			req: &giphy.UploadRequest{
+				SourceURL: "https://example.com/a.gif",
+				Tags:      []string{"orijtech", "go"},
+				Username:  "orijtech",
+			},
+			wantID: "from-url",
+		},
+		4: {
+			req: &giphy.UploadRequest{
+				File: bytes.NewReader(body),
+				Size: int64(len(body)),
+				Tags: []string{"orijtech", "go"},
+				OnProgress: func(sent, total int64) {
+					lastSent, lastTotal = sent, total
+				},
+			},
+			wantID: fmt.Sprintf("from-file-%d", len(body)),
+		},
+		5: {
+			req:     &giphy.UploadRequest{Path: "./testdata/non-existent.gif"},
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		id, err := client.Upload(context.Background(), tt.req)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: want non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: err: %v", i, err)
+			continue
+		}
+		if id != tt.wantID {
+			t.Errorf("#%d: gotID: %q wantID: %q", i, id, tt.wantID)
+		}
+	}
+
+	if want := int64(len(body)); lastSent != want || lastTotal != want {
+		t.Errorf("progress: gotSent: %d gotTotal: %d want: %d", lastSent, lastTotal, want)
+	}
+}
+
+func TestUploadServerError(t *testing.T) {
+	server := uploadServer()
+	defer server.Close()
+
+	client, err := giphy.NewClient(testAPIKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetUploadURL(server.URL)
+
+	_, err = client.Upload(context.Background(), &giphy.UploadRequest{
+		File: strings.NewReader("GIF89a"),
+		Tags: []string{"orijtech", "go"},
+	})
+	if err == nil {
+		t.Fatal("expected an unauthorized error")
+	}
+}
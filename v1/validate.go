@@ -0,0 +1,165 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Endpoint is the route of a GIPHY API that takes a Request.
+type Endpoint string
+
+const (
+	EndpointSearch           Endpoint = "/gifs/search"
+	EndpointTrending         Endpoint = "/gifs/trending"
+	EndpointRandom           Endpoint = "/gifs/random"
+	EndpointTranslate        Endpoint = "/gifs/translate"
+	EndpointSearchStickers   Endpoint = "/stickers/search"
+	EndpointTrendingStickers Endpoint = "/stickers/trending"
+	EndpointRandomSticker    Endpoint = "/stickers/random"
+	EndpointSearchClips      Endpoint = "/clips/search"
+	EndpointTrendingClips    Endpoint = "/clips/trending"
+	EndpointSearchChannels   Endpoint = "/channels/search"
+)
+
+// Limits that GIPHY enforces on requests.
+const (
+	MaxQueryLength  = 50
+	MaxLimitPerPage = 50
+	MaxOffset       = 4999
+)
+
+func (e Endpoint) requiresQuery() bool {
+	switch e {
+	case EndpointSearch, EndpointTranslate, EndpointSearchStickers,
+		EndpointSearchClips, EndpointSearchChannels:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e Endpoint) isPaged() bool {
+	switch e {
+	case EndpointRandom, EndpointRandomSticker, EndpointTranslate:
+		return false
+	default:
+		return true
+	}
+}
+
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fe.Field, fe.Reason)
+}
+
+// ValidationError collects every problem that
+// Request.Validate found with a request.
+type ValidationError struct {
+	Endpoint Endpoint      `json:"endpoint"`
+	Errors   []*FieldError `json:"errors"`
+}
+
+func (ve *ValidationError) Error() string {
+	reasons := make([]string, 0, len(ve.Errors))
+	for _, fe := range ve.Errors {
+		reasons = append(reasons, fe.Error())
+	}
+	return fmt.Sprintf("invalid request for %q: %s", ve.Endpoint, strings.Join(reasons, "; "))
+}
+
+func (r Rating) isKnown() bool {
+	switch r {
+	case RatingPG, RatingPG13, RatingR, RatingGeneral, RatingYouth:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f Format) isKnown() bool {
+	return f == FormatHTMl || f == FormatJSON
+}
+
+func (so SortOrder) isKnown() bool {
+	return so == SortRecent || so == SortRelevant
+}
+
+// Validate checks that req can be sent to endpoint, returning
+// a *ValidationError that lists each offending field if not.
+// A nil Request is validated as a blank one.
+func (req *Request) Validate(endpoint Endpoint) error {
+	if req == nil {
+		req = new(Request)
+	}
+
+	var errs []*FieldError
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, &FieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	query := strings.TrimSpace(req.Query)
+	if endpoint.requiresQuery() && query == "" {
+		addErr("Query", "is required")
+	}
+	if n := utf8.RuneCountInString(query); n > MaxQueryLength {
+		addErr("Query", "has %d characters, the maximum is %d", n, MaxQueryLength)
+	}
+
+	if req.Rating != "" && !req.Rating.isKnown() {
+		addErr("Rating", "unknown rating %q", req.Rating)
+	}
+	if req.Format != "" && !req.Format.isKnown() {
+		addErr("Format", "unknown format %q", req.Format)
+	}
+	// Aliases such as "iw" are supported and sent as their canonical code.
+	if req.Language != "" && !req.Language.IsSupported() {
+		addErr("Language", "unknown language %q", req.Language)
+	}
+	if req.SortBy != "" && !req.SortBy.isKnown() {
+		addErr("SortBy", "unknown sort order %q", req.SortBy)
+	}
+
+	if req.LimitPerPage > MaxLimitPerPage {
+		addErr("LimitPerPage", "is %d, the maximum is %d", req.LimitPerPage, MaxLimitPerPage)
+	}
+	if endpoint.isPaged() && req.MaxPageNumber > 0 {
+		limit := req.LimitPerPage
+		if limit == 0 {
+			// GIPHY's default page size.
+			limit = 25
+		}
+		if lastOffset := (req.MaxPageNumber - 1) * limit; lastOffset > MaxOffset {
+			addErr("MaxPageNumber", "reaches offset %d, the maximum is %d", lastOffset, MaxOffset)
+		}
+	}
+	if req.ThrottleDurationMs < NoThrottle {
+		addErr("ThrottleDurationMs", "is %d, expecting NoThrottle or a non-negative value", req.ThrottleDurationMs)
+	}
+	if req.Filters != nil {
+		req.Filters.validate(addErr)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Endpoint: endpoint, Errors: errs}
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package giphy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/orijtech/giphy/v1"
+)
+
+func TestRequestValidate(t *testing.T) {
+	tests := [...]struct {
+		req        *giphy.Request
+		endpoint   giphy.Endpoint
+		wantFields []string
+	}{
+		0: {req: nil, endpoint: giphy.EndpointTrending},
+		1: {req: nil, endpoint: giphy.EndpointSearch, wantFields: []string{"Query"}},
+		2: {req: &giphy.Request{Query: "   "}, endpoint: giphy.EndpointTranslate, wantFields: []string{"Query"}},
+		3: {
+			req: &giphy.Request{
+				Query:    "cats",
+				Rating:   giphy.RatingPG13,
+				Format:   giphy.FormatJSON,
+				Language: giphy.LangEnglish,
+				SortBy:   giphy.SortRecent,
+			},
+			endpoint: giphy.EndpointSearch,
+		},
+		4: {
+			req: &giphy.Request{
+				Query:    strings.Repeat("ü", giphy.MaxQueryLength+1),
+				Rating:   "nc-17",
+				Format:   "xml",
+				Language: "xx",
+				SortBy:   "oldest",
+			},
+			endpoint:   giphy.EndpointSearchStickers,
+			wantFields: []string{"Query", "Rating", "Format", "Language", "SortBy"},
+		},
+		5: {
+			req:        &giphy.Request{LimitPerPage: 51, ThrottleDurationMs: -2},
+			endpoint:   giphy.EndpointTrending,
+			wantFields: []string{"LimitPerPage", "ThrottleDurationMs"},
+		},
+		6: {
+			req:        &giphy.Request{LimitPerPage: 50, MaxPageNumber: 101},
+			endpoint:   giphy.EndpointTrending,
+			wantFields: []string{"MaxPageNumber"},
+		},
+		7: {
+			req:      &giphy.Request{LimitPerPage: 50, MaxPageNumber: 100},
+			endpoint: giphy.EndpointTrending,
+		},
+		8: {
+			// Random does not page so MaxPageNumber is irrelevant.
+			req:      &giphy.Request{Tag: "cats", MaxPageNumber: 1000},
+			endpoint: giphy.EndpointRandom,
+		},
+	}
+
+	for i, tt := range tests {
+		err := tt.req.Validate(tt.endpoint)
+		if len(tt.wantFields) == 0 {
+			if err != nil {
+				t.Errorf("#%d: unexpected err: %v", i, err)
+			}
+			continue
+		}
+
+		var verr *giphy.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("#%d: gotErr: %v want a *ValidationError", i, err)
+			continue
+		}
+		if verr.Endpoint != tt.endpoint {
+			t.Errorf("#%d: gotEndpoint: %q wantEndpoint: %q", i, verr.Endpoint, tt.endpoint)
+		}
+		var gotFields []string
+		for _, fe := range verr.Errors {
+			gotFields = append(gotFields, fe.Field)
+			if !strings.Contains(err.Error(), fe.Error()) {
+				t.Errorf("#%d: %q is missing from %q", i, fe.Error(), err.Error())
+			}
+		}
+		if !reflect.DeepEqual(gotFields, tt.wantFields) {
+			t.Errorf("#%d: gotFields: %q wantFields: %q", i, gotFields, tt.wantFields)
+		}
+	}
+}
+
+type failingTransport struct {
+	t *testing.T
+}
+
+func (ft *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ft.t.Errorf("unexpected HTTP request to %s", req.URL.Path)
+	return nil, errUnimplemented
+}
+
+func TestClientValidatesBeforeHTTP(t *testing.T) {
+	client, err := giphy.NewClient(testAPIKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPRoundTripper(&failingTransport{t: t})
+
+	ctx := context.Background()
+	badRating := &giphy.Request{Query: "cats", Rating: "nc-17"}
+	calls := map[string]func() error{
+		"Search": func() error {
+			_, err := client.Search(ctx, &giphy.Request{})
+			return err
+		},
+		"Trending": func() error {
+			_, err := client.Trending(ctx, badRating)
+			return err
+		},
+		"SearchChannels": func() error {
+			_, err := client.SearchChannels(ctx, nil)
+			return err
+		},
+		"RandomGIF": func() error {
+			_, err := client.RandomGIF(ctx, badRating)
+			return err
+		},
+		"Translate": func() error {
+			_, err := client.Translate(ctx, nil)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		var verr *giphy.ValidationError
+		if err := call(); !errors.As(err, &verr) {
+			t.Errorf("%s: gotErr: %v want a *ValidationError", name, err)
+		}
+	}
+}